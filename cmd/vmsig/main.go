@@ -0,0 +1,321 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * main.go
+ * ---
+ * Last Modified: 20/10/2026 03:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+// Command vmsig helps edit signature files safely.
+//
+//	vmsig validate FILE...            check the schema, duplicates and patterns
+//	vmsig diff OLD NEW                show what changed between two files
+//	vmsig test [-base FILE] FILE DIR  replay FILE against every snapshot in DIR
+//
+// FILE can be a plain signature file or a signed bundle, bundles have their
+// signature checked against the pinned release key. Snapshots are JSON files
+// in the shape of check.Snapshot or regedit exports (.reg), test reports
+// every snapshot whose verdict, VM or not and the vendor, differs between the
+// base (the embedded signatures by default) and FILE. Evidence that's only
+// added or reordered doesn't count.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/Inspect-Element-Ltd/vm/internal/check"
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "validate":
+		err = validate(os.Stdout, os.Args[2:])
+	case "diff":
+		err = diff(os.Stdout, os.Args[2:])
+	case "test":
+		err = test(os.Stdout, os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: vmsig validate FILE...")
+	fmt.Fprintln(os.Stderr, "       vmsig diff OLD NEW")
+	fmt.Fprintln(os.Stderr, "       vmsig test [-base FILE] FILE SNAPSHOT_DIR")
+	os.Exit(2)
+}
+
+// load reads a signature file or a signed bundle.
+func load(path string) (*signature.File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var file *signature.File
+	if _, ok := probe["payload"]; ok {
+		file, err = signature.Open(data, signature.PublicKey)
+	} else {
+		file, err = signature.Parse(data)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return file, nil
+}
+
+func validate(w io.Writer, args []string) error {
+	if len(args) == 0 {
+		usage()
+	}
+
+	failed := 0
+	for _, path := range args {
+		file, err := load(path)
+		if err != nil {
+			fmt.Fprintln(w, err)
+			failed++
+			continue
+		}
+
+		fmt.Fprintf(w, "%s: ok, version %d\n", path, file.Version)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files are invalid", failed, len(args))
+	}
+
+	return nil
+}
+
+func diff(w io.Writer, args []string) error {
+	if len(args) != 2 {
+		usage()
+	}
+
+	old, err := load(args[0])
+	if err != nil {
+		return err
+	}
+
+	updated, err := load(args[1])
+	if err != nil {
+		return err
+	}
+
+	if old.Version != updated.Version {
+		fmt.Fprintf(w, "version %d -> %d\n", old.Version, updated.Version)
+	}
+
+	for _, name := range union(keys(old.Vendors), keys(updated.Vendors)) {
+		before, after := old.Vendors[name], updated.Vendors[name]
+
+		beforeLists, afterLists := before.Lists(), after.Lists()
+		for _, field := range keys(afterLists) {
+			printChanges(w, name+" "+field, beforeLists[field], afterLists[field])
+		}
+
+		for _, path := range union(keys(before.RegistryValues), keys(after.RegistryValues)) {
+			printChanges(w, name+" registry_values "+path, before.RegistryValues[path], after.RegistryValues[path])
+		}
+	}
+
+	printChanges(w, "nat_defaults", natNames(old.NATDefaults), natNames(updated.NATDefaults))
+	printChanges(w, "smbios_placeholders", old.SMBIOSPlaceholders, updated.SMBIOSPlaceholders)
+	printChanges(w, "passthrough registry_keys", old.Passthrough.RegistryKeys, updated.Passthrough.RegistryKeys)
+	printChanges(w, "passthrough displays", pairs(old.Passthrough.Displays), pairs(updated.Passthrough.Displays))
+	printChanges(w, "passthrough gpu_vendors", pairs(old.Passthrough.GPUVendors), pairs(updated.Passthrough.GPUVendors))
+	if old.Passthrough.IVSHMEM != updated.Passthrough.IVSHMEM {
+		fmt.Fprintf(w, "passthrough ivshmem %s -> %s\n", old.Passthrough.IVSHMEM, updated.Passthrough.IVSHMEM)
+	}
+	printChanges(w, "compatibility_layers registry_keys", old.CompatibilityLayers.RegistryKeys, updated.CompatibilityLayers.RegistryKeys)
+
+	return nil
+}
+
+func test(w io.Writer, args []string) error {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	basePath := flags.String("base", "", "signature file to compare against, the embedded signatures if empty")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		usage()
+	}
+
+	base, err := signature.Parse(signature.Embedded)
+	if *basePath != "" {
+		base, err = load(*basePath)
+	}
+	if err != nil {
+		return err
+	}
+
+	file, err := load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	for _, pattern := range []string{"*.json", "*.reg"} {
+		matches, err := filepath.Glob(filepath.Join(flags.Arg(1), pattern))
+		if err != nil {
+			return err
+		}
+		paths = append(paths, matches...)
+	}
+
+	changed := 0
+	for _, path := range paths {
+		snap, err := loadSnapshot(path)
+		if err != nil {
+			return err
+		}
+
+		before := verdictOf(check.Replay(snap, base))
+		after := verdictOf(check.Replay(snap, file))
+		if before.vm != after.vm || before.vendor != after.vendor {
+			// The reason is only context, it's whichever evidence came first.
+			reason := after.reason
+			if !after.vm {
+				reason = before.reason
+			}
+			fmt.Fprintf(w, "%s: %s -> %s, %s\n", snap.Name, before, after, reason)
+			changed++
+		}
+	}
+
+	fmt.Fprintf(w, "%d of %d snapshots changed verdict\n", changed, len(paths))
+	return nil
+}
+
+// loadSnapshot reads a JSON snapshot or a regedit export.
+func loadSnapshot(path string) (*check.Snapshot, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if filepath.Ext(path) == ".reg" {
+		return check.ParseRegFile(name, f)
+	}
+
+	var snap check.Snapshot
+	if err := json.NewDecoder(f).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if snap.Name == "" {
+		snap.Name = name
+	}
+
+	return &snap, nil
+}
+
+// verdict is what Check would return for a report.
+type verdict struct {
+	vm     bool
+	vendor string
+	reason string
+}
+
+func verdictOf(r *check.Report) verdict {
+	vm, vendor, reason := r.Verdict()
+	return verdict{vm: vm, vendor: vendor, reason: reason}
+}
+
+func (v verdict) String() string {
+	if !v.vm {
+		return "not a VM"
+	}
+
+	return fmt.Sprintf("VM (%s)", v.vendor)
+}
+
+func printChanges(w io.Writer, label string, before []string, after []string) {
+	in := func(list []string, value string) bool {
+		for _, v := range list {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, value := range before {
+		if !in(after, value) {
+			fmt.Fprintf(w, "- %s: %s\n", label, value)
+		}
+	}
+	for _, value := range after {
+		if !in(before, value) {
+			fmt.Fprintf(w, "+ %s: %s\n", label, value)
+		}
+	}
+}
+
+func natNames(nats []signature.NATDefault) []string {
+	names := make([]string, len(nats))
+	for i, nat := range nats {
+		names[i] = fmt.Sprintf("%+v", nat)
+	}
+
+	return names
+}
+
+func pairs(m map[string]string) []string {
+	list := make([]string, 0, len(m))
+	for _, key := range keys(m) {
+		list = append(list, key+" = "+m[key])
+	}
+
+	return list
+}
+
+func keys[V any](m map[string]V) []string {
+	list := make([]string, 0, len(m))
+	for key := range m {
+		list = append(list, key)
+	}
+	sort.Strings(list)
+
+	return list
+}
+
+func union(a []string, b []string) []string {
+	seen := make(map[string]bool)
+	for _, key := range append(a, b...) {
+		seen[key] = true
+	}
+
+	return keys(seen)
+}
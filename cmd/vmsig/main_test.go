@@ -0,0 +1,133 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * main_test.go
+ * ---
+ * Last Modified: 20/10/2026 02:55PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sealed writes testdata/new.json signed with a throwaway key to a temporary file.
+func sealed(t *testing.T) string {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile("testdata/new.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := signature.Seal(data, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if err := os.WriteFile(path, bundle, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     func(w *bytes.Buffer) error
+		fails   bool
+		output  []string // Lines the output has to contain.
+		missing []string // Lines it mustn't.
+	}{
+		{
+			name: "validate",
+			run: func(w *bytes.Buffer) error {
+				return validate(w, []string{"testdata/old.json", "testdata/new.json"})
+			},
+			output: []string{"testdata/old.json: ok, version 1", "testdata/new.json: ok, version 2"},
+		},
+		{
+			name: "validate invalid",
+			run: func(w *bytes.Buffer) error {
+				return validate(w, []string{"testdata/old.json", "testdata/invalid.json"})
+			},
+			fails:  true,
+			output: []string{"testdata/old.json: ok, version 1", `testdata/invalid.json: VMware: registry_keys lists "HKLM\\SOFTWARE\\VMware, Inc." more than once`},
+		},
+		{
+			name: "validate bundle without a key",
+			run: func(w *bytes.Buffer) error {
+				return validate(w, []string{sealed(t)})
+			},
+			fails:  true,
+			output: []string{"no release key configured"},
+		},
+		{
+			name: "diff",
+			run: func(w *bytes.Buffer) error {
+				return diff(w, []string{"testdata/old.json", "testdata/new.json"})
+			},
+			output: []string{
+				"version 1 -> 2",
+				`+ VMware registry_keys: HKLM\SOFTWARE\VMware, Inc.`,
+				`+ VirtualBox registry_keys: HKLM\SOFTWARE\Oracle\VirtualBox Guest Additions`,
+			},
+			missing: []string{`VMware registry_keys: HKLM\SOFTWARE\VMware, Inc.\VMware Tools`},
+		},
+		{
+			name: "test",
+			run: func(w *bytes.Buffer) error {
+				return test(w, []string{"-base", "testdata/old.json", "testdata/new.json", "testdata/snapshots"})
+			},
+			output: []string{
+				`virtualbox: not a VM -> VM (VirtualBox), HKLM\SOFTWARE\Oracle\VirtualBox Guest Additions found in Registry`,
+				"1 of 3 snapshots changed verdict",
+			},
+			// The new key only comes first in the reasons, VMware stays VMware.
+			missing: []string{"vmware:", "bare:"},
+		},
+		{
+			name: "test same file",
+			run: func(w *bytes.Buffer) error {
+				return test(w, []string{"-base", "testdata/new.json", "testdata/new.json", "testdata/snapshots"})
+			},
+			output: []string{"0 of 3 snapshots changed verdict"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var w bytes.Buffer
+			if err := test.run(&w); (err != nil) != test.fails {
+				t.Fatalf("error is %v, expected one: %v", err, test.fails)
+			}
+
+			output := w.String()
+			for _, line := range test.output {
+				if !strings.Contains(output, line+"\n") {
+					t.Errorf("output doesn't contain %q:\n%s", line, output)
+				}
+			}
+			for _, line := range test.missing {
+				if strings.Contains(output, line) {
+					t.Errorf("output contains %q:\n%s", line, output)
+				}
+			}
+		})
+	}
+}
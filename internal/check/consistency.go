@@ -0,0 +1,64 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * consistency.go
+ * ---
+ * Last Modified: 19/10/2026 10:31AM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import "fmt"
+
+// Consistency compares the observations made by the other checks and flags
+// contradictions between them, e.g. CPUID claiming bare metal while a PCI
+// device belongs to a hypervisor.
+//
+// Anti-detection setups tend to patch some signals but rarely all of them,
+// so this must run after every other check. Spoofed values count as
+// physical claims since hiding a hypervisor is the point of spoofing them.
+func Consistency(r *Report) {
+	var physical, virtual []Observation
+	for _, o := range r.Observations {
+		if o.Virtual {
+			virtual = append(virtual, o)
+		} else {
+			physical = append(physical, o)
+		}
+	}
+
+	// One contradiction per pair of sources is enough, a VM with six virtio
+	// devices doesn't need six reports saying CPUID disagrees with PCI.
+	seen := make(map[[2]string]bool)
+	for _, p := range physical {
+		for _, v := range virtual {
+			pair := [2]string{p.Source, v.Source}
+			if p.Source == v.Source || seen[pair] {
+				continue
+			}
+			seen[pair] = true
+
+			r.Evidence = append(r.Evidence, Evidence{
+				Class:        ClassCloaking,
+				Vendor:       v.Vendor,
+				Reason:       fmt.Sprintf("%s claims %s but %s claims %s", p.Source, describe(p), v.Source, describe(v)),
+				Observations: []Observation{p, v},
+			})
+		}
+	}
+}
+
+func describe(o Observation) string {
+	if o.Spoofed {
+		return fmt.Sprintf("%s (spoofed)", o.Value)
+	}
+
+	if o.Vendor == "" || o.Vendor == o.Value {
+		return o.Value
+	}
+
+	return fmt.Sprintf("%s (%s)", o.Value, o.Vendor)
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * cpuid.go
+ * ---
+ * Last Modified: 20/10/2026 10:05AM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"github.com/klauspost/cpuid/v2"
+	"runtime"
+)
+
+// CPUID checks the CPU vendor for a known hypervisor.
+//
+// A missing hypervisor bit is recorded as a physical observation, a set bit
+// on its own is not since Windows hosts with VBS enabled also set it. Only
+// x86 has the bit, on ARM it always reads as missing.
+func CPUID(r *Report) {
+	switch cpuid.CPU.VendorID {
+	case cpuid.MSVM, cpuid.KVM, cpuid.VMware, cpuid.XenHVM, cpuid.Bhyve:
+		r.Add(Evidence{
+			Class:  ClassVM,
+			Vendor: cpuid.CPU.VendorString,
+			Reason: "CPUID",
+			Observations: []Observation{
+				{Source: "CPUID", Value: cpuid.CPU.VendorString, Vendor: cpuid.CPU.VendorString, Virtual: true},
+			},
+		})
+		return
+	default:
+		break
+	}
+
+	if (runtime.GOARCH == "amd64" || runtime.GOARCH == "386") && !cpuid.CPU.VM() {
+		r.Observe(Observation{Source: "CPUID", Value: "no hypervisor bit"})
+	}
+}
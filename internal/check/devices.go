@@ -0,0 +1,169 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * devices.go
+ * ---
+ * Last Modified: 19/10/2026 11:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pnpDevice is a Windows Plug and Play device.
+type pnpDevice struct {
+	instanceID    string
+	hardwareIDs   []string
+	compatibleIDs []string
+	friendlyName  string
+	manufacturer  string
+	// Whether the device is attached right now, enumerations of devices that
+	// were attached once stay in the registry.
+	present bool
+}
+
+// hardwareIDVendor returns the vendor a hardware or compatible ID belongs to,
+// PCI IDs go by their vendor ID, everything else by the hardware_ids patterns.
+func (s *signatureSet) hardwareIDVendor(id string) string {
+	if rest, ok := strings.CutPrefix(strings.ToUpper(id), `PCI\VEN_`); ok && len(rest) >= 4 {
+		return s.pciVendors[strings.ToLower(rest[:4])]
+	}
+
+	for _, vendor := range vendorNames(s.hardwareIDs) {
+		for _, pattern := range s.hardwareIDs[vendor] {
+			if matchSegment(pattern, id) {
+				return vendor
+			}
+		}
+	}
+
+	return ""
+}
+
+// matchDevices checks the hardware and compatible IDs of every device, each
+// device is reported once for the first ID that matches.
+func (s *signatureSet) matchDevices(devices []pnpDevice, r *Report) {
+	for _, device := range devices {
+		for _, id := range append(device.hardwareIDs, device.compatibleIDs...) {
+			vendor := s.hardwareIDVendor(id)
+			if vendor == "" {
+				continue
+			}
+
+			name := device.friendlyName
+			if name == "" {
+				name = device.instanceID
+			}
+			if device.manufacturer != "" {
+				name = fmt.Sprintf("%s (%s)", name, device.manufacturer)
+			}
+
+			// A device that might not be attached anymore only hints at a VM,
+			// e.g. a disk that was imaged from one.
+			if !device.present {
+				r.Add(Evidence{
+					Class:  ClassVM,
+					Vendor: vendor,
+					Reason: fmt.Sprintf("Device %s with hardware ID %s was attached at some point", name, id),
+					Weak:   true,
+				})
+				break
+			}
+
+			source := "Device"
+			if strings.HasPrefix(strings.ToUpper(id), `PCI\`) {
+				source = "PCI"
+			}
+
+			r.Add(Evidence{
+				Class:  ClassVM,
+				Vendor: vendor,
+				Reason: fmt.Sprintf("Device %s has hardware ID %s", name, id),
+				Observations: []Observation{
+					{Source: source, Value: id, Vendor: vendor, Virtual: true},
+				},
+			})
+			break
+		}
+	}
+}
+
+// registryDevices reads the devices Windows has ever enumerated from the Enum
+// key, for registries that aren't live. Which of them are present isn't known.
+func registryDevices(reg registryReader) []pnpDevice {
+	enumKey, buses, ok := controlSetKey(reg, "Enum")
+	if !ok {
+		return nil
+	}
+
+	var devices []pnpDevice
+	for _, bus := range buses {
+		busKey := joinRegistryPath(enumKey, bus)
+		for _, deviceID := range registrySubKeys(reg, busKey) {
+			deviceKey := joinRegistryPath(busKey, deviceID)
+			for _, instance := range registrySubKeys(reg, deviceKey) {
+				keyHandle, err := reg.OpenKey(joinRegistryPath(deviceKey, instance))
+				if err != nil {
+					continue
+				}
+
+				device := pnpDevice{instanceID: strings.Join([]string{bus, deviceID, instance}, `\`)}
+				if value, err := keyHandle.Value("HardwareID"); err == nil && !value.numeric {
+					device.hardwareIDs = value.strings
+				}
+				if value, err := keyHandle.Value("CompatibleIDs"); err == nil && !value.numeric {
+					device.compatibleIDs = value.strings
+				}
+				device.friendlyName = deviceString(keyHandle, "FriendlyName")
+				if device.friendlyName == "" {
+					device.friendlyName = deviceString(keyHandle, "DeviceDesc")
+				}
+				device.manufacturer = deviceString(keyHandle, "Mfg")
+				keyHandle.Close()
+
+				// Devices without IDs, e.g. in a .reg export, still have their device ID.
+				if len(device.hardwareIDs) == 0 {
+					device.hardwareIDs = []string{bus + `\` + deviceID}
+				}
+
+				devices = append(devices, device)
+			}
+		}
+	}
+
+	return devices
+}
+
+// registrySubKeys lists the subkeys of a key, nil if it can't be read.
+func registrySubKeys(reg registryReader, keyPath string) []string {
+	keyHandle, err := reg.OpenKey(keyPath)
+	if err != nil {
+		return nil
+	}
+	defer keyHandle.Close()
+
+	names, _ := keyHandle.SubKeyNames()
+	return names
+}
+
+// deviceString reads a device description, which are usually indirect strings
+// like "@oem3.inf,%vmsvga%;VMware SVGA 3D", only the part after the ';' is kept.
+func deviceString(keyHandle registryKey, name string) string {
+	value, err := keyHandle.Value(name)
+	if err != nil || value.numeric || len(value.strings) == 0 {
+		return ""
+	}
+
+	description := value.strings[0]
+	if i := strings.LastIndex(description, ";"); i != -1 && strings.HasPrefix(description, "@") {
+		description = description[i+1:]
+	}
+
+	return description
+}
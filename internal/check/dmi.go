@@ -0,0 +1,46 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * dmi.go
+ * ---
+ * Last Modified: 19/10/2026 11:59PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"github.com/Inspect-Element-Ltd/vm/internal/firmware"
+	"github.com/Inspect-Element-Ltd/vm/internal/util"
+	"io/fs"
+	"path"
+)
+
+// Where sysfs exposes the SMBIOS strings, relative to the root.
+const sysDMI = "sys/class/dmi/id"
+
+var (
+	dmiFields = []string{
+		"sys_vendor",
+		"product_name",
+		"board_vendor",
+		"bios_vendor",
+	}
+)
+
+// matchDMI checks the SMBIOS strings in sysfs.
+func (s *signatureSet) matchDMI(fsys fs.FS, r *Report) {
+	var fields []firmware.Field
+	for _, field := range dmiFields {
+		value, err := util.ReadFSString(fsys, path.Join(sysDMI, field))
+		if err != nil || value == "" {
+			continue
+		}
+
+		fields = append(fields, firmware.Field{Name: field, Value: value})
+	}
+
+	s.matchSMBIOSFields("DMI", fields, false, r)
+}
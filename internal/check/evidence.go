@@ -0,0 +1,118 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * evidence.go
+ * ---
+ * Last Modified: 20/10/2026 03:20AM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+// Class describes what a piece of Evidence says about the system.
+type Class string
+
+const (
+	// ClassVM is evidence that the system is running as a virtual machine.
+	ClassVM Class = "VM"
+	// ClassCloaking is evidence that signals contradict each other,
+	// which usually means a VM has been patched to hide itself.
+	ClassCloaking Class = "Cloaking Suspected"
+	// ClassPassthrough is evidence of a VM built for GPU passthrough,
+	// e.g. one running Looking Glass. These are rare on bare metal.
+	ClassPassthrough Class = "GPU Passthrough"
+	// ClassHypervisorInstalled is evidence that hypervisor software is
+	// installed on the host, it says nothing about the system being a VM.
+	ClassHypervisorInstalled Class = "Hypervisor Installed"
+	// ClassCompatibilityLayer is evidence of a layer like Wine or Proton
+	// running Windows programs on another OS, which isn't a VM.
+	ClassCompatibilityLayer Class = "Compatibility Layer"
+)
+
+// Observation is what a single signal claims about the system,
+// whether that claim is "virtual" or "physical".
+type Observation struct {
+	Source  string // Where the value was read from, e.g. "CPUID" or "SMBIOS".
+	Value   string // The raw value that was observed.
+	Vendor  string // The vendor the value belongs to, if known.
+	Virtual bool   // Whether the value only occurs on virtual hardware.
+	Spoofed bool   // Whether the value looks changed to hide the real one, e.g. a locally administered MAC.
+}
+
+// Evidence is a single finding produced by a check.
+type Evidence struct {
+	Class        Class
+	Vendor       string
+	Reason       string
+	Observations []Observation
+	// Weak evidence is only a hint, e.g. a subnet hypervisors use by default.
+	// It adds to the Score but is never a verdict on its own.
+	Weak bool
+	// Root is the offline root the evidence was found in, e.g. a mounted
+	// disk image. It's empty for evidence about the running system.
+	Root string
+	// Metadata is what the guest knows about its VM, e.g. the host name or
+	// tools version, see the Metadata constants. It's only collected once
+	// turned on with CollectGuestMetadata.
+	Metadata map[string]string
+}
+
+// Report collects the Evidence and Observations produced by every check.
+type Report struct {
+	Evidence     []Evidence
+	Observations []Observation
+}
+
+// Add records a piece of evidence along with the observations attached to it.
+func (r *Report) Add(e Evidence) {
+	r.Evidence = append(r.Evidence, e)
+	r.Observations = append(r.Observations, e.Observations...)
+}
+
+// Observe records what a signal claims without it being evidence on its own.
+func (r *Report) Observe(o Observation) {
+	r.Observations = append(r.Observations, o)
+}
+
+// Verdict returns the first VM or GPU passthrough evidence in the report,
+// weak evidence is skipped. Windows containers run on Hyper-V, so evidence
+// for one wins over the Hyper-V evidence found along with it.
+//
+// The vendor and reason will be empty if nothing was found.
+func (r *Report) Verdict() (bool, string, string) {
+	for _, e := range r.Evidence {
+		if e.Class == ClassVM && e.Vendor == VendorWindowsSandbox && !e.Weak {
+			return true, e.Vendor, e.Reason
+		}
+	}
+
+	for _, e := range r.Evidence {
+		if (e.Class == ClassVM || e.Class == ClassPassthrough) && !e.Weak {
+			return true, e.Vendor, e.Reason
+		}
+	}
+
+	return false, "", ""
+}
+
+// Score weighs the evidence in the report, the higher it is the more
+// likely the system is a VM. Anything from 10 up is a VM.
+func (r *Report) Score() int {
+	score := 0
+	for _, e := range r.Evidence {
+		switch {
+		case e.Class == ClassHypervisorInstalled, e.Class == ClassCompatibilityLayer:
+			continue
+		case e.Weak:
+			score += 2
+		case e.Class == ClassCloaking:
+			score += 5
+		default:
+			score += 10
+		}
+	}
+
+	return score
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * files.go
+ * ---
+ * Last Modified: 19/10/2026 10:20PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Where the known folders are on a default install, relative to the system drive.
+var rootFolders = map[string]string{
+	"SystemRoot":        "Windows",
+	"System32":          "Windows/System32",
+	"SysNative":         "Windows/System32",
+	"ProgramFiles":      "Program Files",
+	"ProgramFiles(x86)": "Program Files (x86)",
+}
+
+// isWindowsPath reports whether a file signature is a Windows path starting
+// with a known folder, anything else is a macOS or Linux path.
+func isWindowsPath(file string) bool {
+	_, _, ok := signature.SplitKnownFolder(file)
+	return ok
+}
+
+// rootPath turns a file signature, or a plain path like C:\Windows\..., into
+// a path relative to the root of the drive it's on.
+func rootPath(file string) string {
+	if folder, rest, ok := signature.SplitKnownFolder(file); ok {
+		file = rootFolders[folder] + "/" + rest
+	} else if len(file) >= 3 && file[1] == ':' && file[2] == '\\' {
+		file = file[3:]
+	}
+
+	return strings.Trim(strings.ReplaceAll(file, `\`, "/"), "/")
+}
+
+// findFold looks for name in fsys ignoring case, the way Windows and macOS
+// do, and returns the name it's actually stored under.
+func findFold(fsys fs.FS, name string) (string, bool) {
+	if _, err := fs.Stat(fsys, name); err == nil {
+		return name, true
+	}
+
+	return findFoldIn(fsys, ".", strings.Split(name, "/"))
+}
+
+// findFoldIn follows segments down from dir. Every match is tried since a
+// copy on a case-sensitive filesystem can hold both "Windows" and "windows".
+func findFoldIn(fsys fs.FS, dir string, segments []string) (string, bool) {
+	if len(segments) == 0 {
+		return dir, true
+	}
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return "", false
+	}
+
+	for _, entry := range entries {
+		if !strings.EqualFold(entry.Name(), segments[0]) {
+			continue
+		}
+
+		if found, ok := findFoldIn(fsys, path.Join(dir, entry.Name()), segments[1:]); ok {
+			return found, true
+		}
+	}
+
+	return "", false
+}
+
+// matchRootFiles checks for the files of every vendor below the root of another system.
+func (s *signatureSet) matchRootFiles(fsys fs.FS, r *Report) {
+	for _, vendor := range vendorNames(s.files) {
+		for _, file := range s.files[vendor] {
+			if _, ok := findFold(fsys, rootPath(file)); ok {
+				r.Add(Evidence{Class: ClassVM, Vendor: vendor, Reason: fmt.Sprintf("%s file exists", file)})
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * firmware.go
+ * ---
+ * Last Modified: 19/10/2026 11:59PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"github.com/Inspect-Element-Ltd/vm/internal/firmware"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Where sysfs exposes the raw ACPI tables, relative to the root. Only root can read them.
+const sysACPITables = "sys/firmware/acpi/tables"
+
+// matchSMBIOSFields checks decoded SMBIOS strings, source is where they were
+// read from, e.g. DMI for sysfs. virtual is set when something else in
+// SMBIOS already gave the hypervisor away.
+func (s *signatureSet) matchSMBIOSFields(source string, fields []firmware.Field, virtual bool, r *Report) {
+	for _, field := range fields {
+		if vendor := s.smbiosVendor(field.Value); vendor != "" {
+			virtual = true
+			r.Add(Evidence{
+				Class:  ClassVM,
+				Vendor: vendor,
+				Reason: fmt.Sprintf("%s %s is %s", source, field.Name, field.Value),
+				Observations: []Observation{
+					{Source: "SMBIOS", Value: field.Value, Vendor: vendor, Virtual: true},
+				},
+			})
+		}
+	}
+
+	// Only the system vendor says who built the machine, and only if nothing else in SMBIOS contradicts it.
+	if vendor := firmware.FieldValue(fields, "sys_vendor"); !virtual && vendor != "" && !s.isSMBIOSPlaceholder(vendor) {
+		r.Observe(Observation{Source: "SMBIOS", Value: vendor, Vendor: vendor})
+	}
+}
+
+// matchSMBIOSTable checks a raw SMBIOS table, the strings the same as DMI and
+// the BIOS characteristic hypervisors set to say they're one.
+func (s *signatureSet) matchSMBIOSTable(structures []firmware.Structure, r *Report) {
+	fields := firmware.Fields(structures)
+
+	virtual := firmware.IsVirtualMachine(structures)
+	if virtual {
+		vendor := s.smbiosVendor(firmware.FieldValue(fields, "sys_vendor"))
+		if vendor == "" {
+			vendor = "Generic"
+		}
+
+		r.Add(Evidence{
+			Class:  ClassVM,
+			Vendor: vendor,
+			Reason: "SMBIOS BIOS characteristics say this is a virtual machine",
+			Observations: []Observation{
+				{Source: "SMBIOS", Value: "virtual machine flag", Vendor: vendor, Virtual: true},
+			},
+		})
+	}
+
+	s.matchSMBIOSFields("SMBIOS", fields, virtual, r)
+}
+
+// acpiVendor returns the hypervisor that built an ACPI table and the ID that gave it away.
+func (s *signatureSet) acpiVendor(header firmware.ACPIHeader) (string, string) {
+	for _, vendor := range vendorNames(s.acpiOEMIDs) {
+		for _, id := range s.acpiOEMIDs[vendor] {
+			for _, value := range []string{header.OEMID, header.OEMTableID, header.CreatorID} {
+				if strings.HasPrefix(strings.ToUpper(value), strings.ToUpper(id)) {
+					return vendor, value
+				}
+			}
+		}
+	}
+
+	return "", ""
+}
+
+// matchACPITables checks who built the ACPI tables, each vendor is reported
+// once with every table of theirs.
+func (s *signatureSet) matchACPITables(headers []firmware.ACPIHeader, r *Report) {
+	tables := make(map[string][]string)
+	ids := make(map[string]string)
+	for _, header := range headers {
+		if vendor, id := s.acpiVendor(header); vendor != "" {
+			tables[vendor] = append(tables[vendor], header.Signature)
+			if ids[vendor] == "" {
+				ids[vendor] = id
+			}
+		}
+	}
+
+	for _, vendor := range vendorNames(tables) {
+		r.Add(Evidence{
+			Class:  ClassVM,
+			Vendor: vendor,
+			Reason: fmt.Sprintf("ACPI tables %s were built by %s", strings.Join(tables[vendor], ", "), ids[vendor]),
+			Observations: []Observation{
+				{Source: "ACPI", Value: ids[vendor], Vendor: vendor, Virtual: true},
+			},
+		})
+	}
+}
+
+// readACPITables reads the headers of the ACPI tables in sysfs.
+func readACPITables(fsys fs.FS) []firmware.ACPIHeader {
+	entries, err := fs.ReadDir(fsys, sysACPITables)
+	if err != nil {
+		return nil
+	}
+
+	var headers []firmware.ACPIHeader
+	for _, entry := range entries {
+		// dynamic and data hold tables loaded later and BERT/BGRT payloads.
+		if entry.IsDir() {
+			continue
+		}
+
+		f, err := fsys.Open(path.Join(sysACPITables, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		table := make([]byte, 36)
+		_, err = io.ReadFull(f, table)
+		f.Close()
+		if err != nil {
+			continue
+		}
+
+		if header, err := firmware.ParseACPIHeader(table); err == nil {
+			headers = append(headers, header)
+		}
+	}
+
+	return headers
+}
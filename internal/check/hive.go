@@ -0,0 +1,192 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * hive.go
+ * ---
+ * Last Modified: 20/10/2026 03:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"github.com/Inspect-Element-Ltd/vm/internal/regf"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Where Windows loads each hive file, keyed by the file name.
+var hiveMounts = map[string]string{
+	"SYSTEM":       `HKLM\SYSTEM`,
+	"SOFTWARE":     `HKLM\SOFTWARE`,
+	"SAM":          `HKLM\SAM`,
+	"SECURITY":     `HKLM\SECURITY`,
+	"NTUSER.DAT":   `HKCU`,
+	"USRCLASS.DAT": `HKCU\Software\Classes`,
+}
+
+// HiveMount returns where Windows loads a hive file, going by its name.
+func HiveMount(path string) (string, bool) {
+	mount, ok := hiveMounts[strings.ToUpper(filepath.Base(path))]
+	return mount, ok
+}
+
+// OfflineRegistry matches the registry signatures against hive files pulled
+// from another machine, keyed by where they're mounted, see HiveMount.
+func OfflineRegistry(r *Report, hives map[string]*regf.Hive) error {
+	reg, err := newHiveRegistry(hives)
+	if err != nil {
+		return err
+	}
+
+	s := sigs()
+	s.matchRegistry(reg, r)
+	s.matchServices(s.registryServices(reg), r)
+	s.matchDevices(registryDevices(reg), r)
+	matchSandbox(reg, "", nil, r)
+	s.matchWine(reg, wineState{}, r)
+	return nil
+}
+
+// hiveRegistry is a registry read from hive files.
+type hiveRegistry struct {
+	mounts []hiveMount // Longest path first.
+}
+
+type hiveMount struct {
+	path string
+	root *regf.Key
+	// The control set CurrentControlSet links to, the link only exists on a running system.
+	currentControlSet string
+}
+
+func newHiveRegistry(hives map[string]*regf.Hive) (*hiveRegistry, error) {
+	reg := &hiveRegistry{}
+
+	for mount, hive := range hives {
+		root, err := hive.Root()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", mount, err)
+		}
+
+		m := hiveMount{path: strings.Join(splitRegistryPath(mount), `\`), root: root}
+		if strings.EqualFold(m.path, `HKLM\SYSTEM`) {
+			m.currentControlSet = currentControlSet(root)
+		}
+		reg.mounts = append(reg.mounts, m)
+
+		// HKCR is a view of the machine's classes.
+		if strings.EqualFold(m.path, `HKLM\SOFTWARE`) {
+			if classes, err := root.Open("Classes"); err == nil {
+				reg.mounts = append(reg.mounts, hiveMount{path: "HKCR", root: classes})
+			}
+		}
+	}
+
+	sort.Slice(reg.mounts, func(i, j int) bool {
+		return len(reg.mounts[i].path) > len(reg.mounts[j].path)
+	})
+
+	return reg, nil
+}
+
+// currentControlSet reads which ControlSetNNN the system boots from.
+func currentControlSet(root *regf.Key) string {
+	current := "ControlSet001"
+
+	if key, err := root.Open("Select"); err == nil {
+		if value, err := key.Value("Current"); err == nil {
+			if v := decodeRegistryValue(value.Type, value.Data); v.numeric {
+				current = fmt.Sprintf("ControlSet%03d", v.integer)
+			}
+		}
+	}
+
+	return current
+}
+
+func (reg *hiveRegistry) OpenKey(registryPath string) (registryKey, error) {
+	segments := splitRegistryPath(registryPath)
+
+	for _, m := range reg.mounts {
+		mountSegments := splitRegistryPath(m.path)
+		if len(segments) < len(mountSegments) || !strings.EqualFold(strings.Join(segments[:len(mountSegments)], `\`), m.path) {
+			continue
+		}
+
+		rest := segments[len(mountSegments):]
+		if m.currentControlSet != "" && len(rest) > 0 && strings.EqualFold(rest[0], "CurrentControlSet") {
+			rest = append([]string{m.currentControlSet}, rest[1:]...)
+		}
+
+		key, err := m.root.Open(strings.Join(rest, `\`))
+		if err != nil {
+			return nil, err
+		}
+
+		return hiveKey{key}, nil
+	}
+
+	// Keys above the hives, like HKLM, only hold the hives mounted below them.
+	var subKeys []string
+	for _, m := range reg.mounts {
+		mountSegments := splitRegistryPath(m.path)
+		if len(mountSegments) > len(segments) && strings.EqualFold(strings.Join(mountSegments[:len(segments)], `\`), strings.Join(segments, `\`)) {
+			subKeys = append(subKeys, mountSegments[len(segments)])
+		}
+	}
+	if len(subKeys) > 0 {
+		return &memoryKey{subKeys: subKeys}, nil
+	}
+
+	return nil, errRegistryKeyNotFound
+}
+
+type hiveKey struct {
+	*regf.Key
+}
+
+func (k hiveKey) SubKeyNames() ([]string, error) {
+	keys, err := k.SubKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.Name
+	}
+
+	return names, nil
+}
+
+func (k hiveKey) ValueNames() ([]string, error) {
+	values, err := k.Values()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(values))
+	for i, value := range values {
+		names[i] = value.Name
+	}
+
+	return names, nil
+}
+
+func (k hiveKey) Value(name string) (registryValue, error) {
+	value, err := k.Key.Value(name)
+	if err != nil {
+		return registryValue{}, err
+	}
+
+	return decodeRegistryValue(value.Type, value.Data), nil
+}
+
+func (k hiveKey) Close() error {
+	return nil
+}
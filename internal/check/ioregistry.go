@@ -0,0 +1,134 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * ioregistry.go
+ * ---
+ * Last Modified: 20/10/2026 04:10AM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IORegistry properties the vendor names are read from, on any entry.
+var ioregistryVendorProperties = []string{"Manufacturer", "Vendor Name", "USB Vendor Name"}
+
+// macPlatform is what the IOPlatformExpertDevice entry says about the machine.
+type macPlatform struct {
+	serial       string // IOPlatformSerialNumber.
+	manufacturer string
+	model        string
+	boardID      string // board-id, e.g. Mac-937A206F2EE63C01.
+}
+
+// macMinMemory is the least a real Mac has shipped with in years.
+const macMinMemory = 4 << 30
+
+// matchPlatform checks the IOPlatformExpertDevice entry.
+func matchPlatform(platform macPlatform, r *Report) {
+	// Most VM software like VMWare, VirtualBox, etc. will have a serial number of "0".
+	if platform.serial == "0" {
+		r.Add(Evidence{
+			Class:  ClassVM,
+			Vendor: "Generic",
+			Reason: "Serial Number is 0",
+			Observations: []Observation{
+				{Source: "IOPlatformSerialNumber", Value: platform.serial, Virtual: true},
+			},
+		})
+	}
+
+	// If the board manufacturer doesn't contain "Apple" then it's likely a VM,
+	// otherwise it's a physical claim the consistency check can compare against.
+	// Apple's own VMs say Apple too, their model gives them away in hw.model.
+	switch {
+	case strings.Contains(platform.manufacturer, "Apple"):
+		if !strings.HasPrefix(platform.model, "VirtualMac") {
+			r.Observe(Observation{Source: "IOPlatformExpertDevice", Value: strings.TrimSpace(platform.manufacturer + " " + platform.model), Vendor: "Apple"})
+		}
+	case platform.manufacturer != "":
+		r.Add(Evidence{
+			Class:  ClassVM,
+			Vendor: "Generic",
+			Reason: fmt.Sprintf("Manufacturer is %s not Apple Inc.", platform.manufacturer),
+			Observations: []Observation{
+				{Source: "IOPlatformExpertDevice", Value: platform.manufacturer, Virtual: true},
+			},
+		})
+	}
+
+	// Apple's board IDs start with Mac-, hypervisors running macOS use VMM-x86_64.
+	if strings.HasPrefix(platform.boardID, "VMM") {
+		r.Add(Evidence{
+			Class:  ClassVM,
+			Vendor: "Generic",
+			Reason: fmt.Sprintf("board-id is %s", platform.boardID),
+			Observations: []Observation{
+				{Source: "board-id", Value: platform.boardID, Virtual: true},
+			},
+		})
+	}
+}
+
+// matchIORegistryVendors checks the Manufacturer and Vendor Name properties
+// found in the IORegistry.
+func (s *signatureSet) matchIORegistryVendors(values []string, r *Report) {
+	for _, value := range values {
+		for _, vendor := range vendorNames(s.ioregistryVendors) {
+			for _, name := range s.ioregistryVendors[vendor] {
+				if strings.Contains(strings.ToLower(value), strings.ToLower(name)) {
+					r.Add(Evidence{
+						Class:  ClassVM,
+						Vendor: vendor,
+						Reason: fmt.Sprintf("Vendor Name contains %s", name),
+						Observations: []Observation{
+							{Source: "IORegistry", Value: value, Vendor: vendor, Virtual: true},
+						},
+					})
+				}
+			}
+		}
+	}
+}
+
+// matchHardwareModel checks hw.model, which is the model identifier on real
+// Macs and whatever the hypervisor picked otherwise.
+func matchHardwareModel(model string, r *Report) {
+	switch {
+	case strings.HasPrefix(model, "VirtualMac"):
+		// Guests of Apple's Virtualization framework, e.g. VirtualMac2,1.
+		r.Add(Evidence{
+			Class:  ClassVM,
+			Vendor: "Apple Virtualization",
+			Reason: fmt.Sprintf("hw.model is %s", model),
+			Observations: []Observation{
+				{Source: "hw.model", Value: model, Vendor: "Apple Virtualization", Virtual: true},
+			},
+		})
+	case !strings.Contains(model, "Mac"):
+		r.Add(Evidence{
+			Class:  ClassVM,
+			Vendor: model,
+			Reason: "hw.model doesn't contain 'Mac'",
+			Observations: []Observation{
+				{Source: "hw.model", Value: model, Virtual: true},
+			},
+		})
+	default:
+		r.Observe(Observation{Source: "hw.model", Value: model, Vendor: "Apple"})
+	}
+}
+
+// matchMemorySize checks hw.memsize, in bytes.
+func matchMemorySize(memSize uint64, r *Report) {
+	// Small Macs exist, so this isn't attached as an observation for the consistency check.
+	if memSize < macMinMemory {
+		r.Add(Evidence{Class: ClassVM, Vendor: "Generic", Reason: "hw.memsize is less than 4GB"})
+	}
+}
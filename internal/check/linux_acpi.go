@@ -0,0 +1,19 @@
+//go:build linux
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * linux_acpi.go
+ * ---
+ * Last Modified: 20/10/2026 01:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+// ACPI checks who built the ACPI tables in /sys/firmware/acpi/tables, they're only readable as root.
+func ACPI(r *Report) {
+	sigs().matchACPITables(readACPITables(liveRoot), r)
+}
@@ -0,0 +1,170 @@
+//go:build linux
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * linux_net.go
+ * ---
+ * Last Modified: 20/10/2026 01:50PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type route struct {
+	iface       string
+	destination string // Hex, little endian, as it appears in /proc/net/route.
+	gateway     string
+	mask        string
+}
+
+// routes reads the IPv4 routing table from /proc/net/route.
+func routes() []route {
+	file, err := os.Open("/proc/net/route")
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var table []route
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Header
+	for scanner.Scan() {
+		// Iface Destination Gateway Flags RefCnt Use Metric Mask MTU Window IRTT
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue
+		}
+
+		table = append(table, route{iface: fields[0], destination: fields[1], gateway: fields[2], mask: fields[7]})
+	}
+
+	return table
+}
+
+// defaultGateway reads the default route from /proc/net/route and the
+// gateway's MAC address from the neighbour cache in /proc/net/arp.
+func defaultGateway() (gateway, bool) {
+	for _, rt := range routes() {
+		if rt.destination != "00000000" || rt.mask != "00000000" {
+			continue
+		}
+
+		// The kernel prints addresses as little endian hex.
+		raw, err := strconv.ParseUint(rt.gateway, 16, 32)
+		if err != nil {
+			return gateway{}, false
+		}
+		ip := net.IPv4(byte(raw), byte(raw>>8), byte(raw>>16), byte(raw>>24)).To4()
+
+		return gateway{iface: rt.iface, address: interfaceIPv4(rt.iface), ip: ip, mac: neighbour(ip)}, true
+	}
+
+	return gateway{}, false
+}
+
+// neighbour looks up the MAC address of ip in /proc/net/arp.
+func neighbour(ip net.IP) net.HardwareAddr {
+	file, err := os.Open("/proc/net/arp")
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Header
+	for scanner.Scan() {
+		// IP address  HW type  Flags  HW address  Mask  Device
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !ip.Equal(net.ParseIP(fields[0])) {
+			continue
+		}
+
+		if mac, err := net.ParseMAC(fields[3]); err == nil {
+			return mac
+		}
+	}
+
+	return nil
+}
+
+func defaultRouteInterface() string {
+	for _, rt := range routes() {
+		if rt.destination == "00000000" && rt.mask == "00000000" {
+			return rt.iface
+		}
+	}
+
+	return ""
+}
+
+// readAdapterInfo reads what the host adapter check needs about iface.
+//
+// A guest's NIC is always bound to a driver for some (virtual) device, where
+// vmnet, vboxnet and virbr adapters are purely software interfaces.
+func readAdapterInfo(iface net.Interface) adapterInfo {
+	_, err := os.Stat(filepath.Join("/sys/class/net", iface.Name, "device"))
+	return adapterInfo{name: iface.Name, unbound: os.IsNotExist(err)}
+}
+
+// nics builds the interface inventory from /sys/class/net/*/device.
+func nics() []nic {
+	entries, err := os.ReadDir("/sys/class/net")
+	if err != nil {
+		return nil
+	}
+
+	var list []nic
+	for _, entry := range entries {
+		device := filepath.Join("/sys/class/net", entry.Name(), "device")
+		if _, err := os.Stat(device); err != nil {
+			continue
+		}
+
+		n := nic{name: entry.Name()}
+		if driver, err := filepath.EvalSymlinks(filepath.Join(device, "driver")); err == nil {
+			n.driver = filepath.Base(driver)
+		}
+		if bus, err := filepath.EvalSymlinks(filepath.Join(device, "subsystem")); err == nil {
+			n.bus = filepath.Base(bus)
+		}
+
+		// virtio-net hangs off a virtio device which in turn is the PCI device.
+		dir := device
+		if resolved, err := filepath.EvalSymlinks(device); err == nil && n.bus == "virtio" {
+			dir = filepath.Dir(resolved)
+		}
+		if id, ok := pciID(dir); ok {
+			n.id = id
+			n.subsystem = pciSubsystemID(dir)
+		}
+
+		list = append(list, n)
+	}
+
+	return list
+}
+
+// isWired reports whether iface is an Ethernet adapter bound to a device.
+func isWired(iface net.Interface) bool {
+	dir := filepath.Join("/sys/class/net", iface.Name)
+	for _, name := range []string{"wireless", "phy80211"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return false
+		}
+	}
+
+	_, err := os.Stat(filepath.Join(dir, "device"))
+	return err == nil
+}
@@ -0,0 +1,78 @@
+//go:build linux
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * linux_passthrough.go
+ * ---
+ * Last Modified: 20/10/2026 10:20AM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Passthrough checks sysfs for the devices GPU passthrough VMs are built with:
+// an IVSHMEM device, a physical GPU behind an emulated root port and an
+// emulated secondary display.
+func Passthrough(r *Report) {
+	s := sigs()
+	devices := pciDevices()
+
+	var gpus, displays []pciDevice
+	for _, device := range devices {
+		if device.id == s.ivshmemDevice {
+			r.Add(Evidence{
+				Class:  ClassPassthrough,
+				Vendor: "QEMU",
+				Reason: fmt.Sprintf("IVSHMEM device %s found at %s", device.id, device.address),
+				Observations: []Observation{
+					{Source: "PCI", Value: device.id, Vendor: "QEMU", Virtual: true},
+				},
+			})
+		}
+
+		// Class 0x03 is a display controller.
+		if !strings.HasPrefix(device.class, "03") {
+			continue
+		}
+
+		if _, ok := s.virtualDisplays[device.id]; ok {
+			displays = append(displays, device)
+		} else if _, ok := s.gpuVendors[pciVendorID(device.id)]; ok {
+			gpus = append(gpus, device)
+		}
+	}
+
+	for _, gpu := range gpus {
+		if vendor, ok := s.pciVendors[pciVendorID(gpu.parent)]; ok {
+			r.Add(Evidence{
+				Class:  ClassPassthrough,
+				Vendor: vendor,
+				Reason: fmt.Sprintf("%s GPU %s sits behind %s bridge %s", s.gpuVendors[pciVendorID(gpu.id)], gpu.id, vendor, gpu.parent),
+				Observations: []Observation{
+					{Source: "PCI", Value: gpu.parent, Vendor: vendor, Virtual: true},
+				},
+			})
+		}
+	}
+
+	// Once per display, next to the first GPU.
+	if len(gpus) == 0 {
+		return
+	}
+	gpu := gpus[0]
+	for _, display := range displays {
+		r.Add(Evidence{
+			Class:  ClassPassthrough,
+			Vendor: "QEMU",
+			Reason: fmt.Sprintf("%s secondary display %s next to %s GPU %s", s.virtualDisplays[display.id], display.id, s.gpuVendors[pciVendorID(gpu.id)], gpu.id),
+		})
+	}
+}
@@ -0,0 +1,48 @@
+//go:build linux
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * linux_pci.go
+ * ---
+ * Last Modified: 20/10/2026 01:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var liveRoot = os.DirFS("/")
+
+// pciDevices reads every device in /sys/bus/pci/devices for the passthrough check.
+func pciDevices() []pciDevice {
+	devices := readPCIDevices(liveRoot)
+
+	for i, device := range devices {
+		// The resolved sysfs path nests a device under the bridge it sits behind.
+		if resolved, err := filepath.EvalSymlinks(filepath.Join("/", sysPCIDevices, device.address)); err == nil {
+			if parent, ok := pciID(filepath.Dir(resolved)); ok {
+				devices[i].parent = parent
+			}
+		}
+	}
+
+	return devices
+}
+
+// pciID reads the vendor:device pair of the PCI device at dir.
+func pciID(dir string) (string, bool) {
+	return readPCIID(liveRoot, strings.TrimPrefix(dir, "/"))
+}
+
+// pciSubsystemID reads the subsystem vendor:device pair of the PCI device at dir.
+func pciSubsystemID(dir string) string {
+	return readPCISubsystemID(liveRoot, strings.TrimPrefix(dir, "/"))
+}
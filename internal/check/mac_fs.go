@@ -0,0 +1,35 @@
+//go:build darwin
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * mac_fs.go
+ * ---
+ * Last Modified: 19/10/2026 09:35PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"os"
+)
+
+// FileSystem checks for guest tools and their kexts.
+func FileSystem(r *Report) {
+	s := sigs()
+	for _, vendor := range vendorNames(s.files) {
+		for _, file := range s.files[vendor] {
+			if isWindowsPath(file) {
+				continue
+			}
+
+			if _, err := os.Stat(file); err == nil {
+				r.Add(Evidence{Class: ClassVM, Vendor: vendor, Reason: fmt.Sprintf("%s file exists", file)})
+			}
+		}
+	}
+}
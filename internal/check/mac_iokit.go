@@ -0,0 +1,121 @@
+//go:build darwin && cgo
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * mac_iokit.go
+ * ---
+ * Last Modified: 20/10/2026 04:10AM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+/*
+#cgo LDFLAGS: -framework IOKit -framework CoreFoundation
+#include <stdlib.h>
+#include <CoreFoundation/CoreFoundation.h>
+#include <IOKit/IOKitLib.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"strings"
+	"unsafe"
+)
+
+var errNoPlatformExpert = errors.New("IOPlatformExpertDevice not found")
+
+// platformExpert reads the IOPlatformExpertDevice entry through IOKit.
+func platformExpert() (macPlatform, error) {
+	className := C.CString("IOPlatformExpertDevice")
+	defer C.free(unsafe.Pointer(className))
+
+	// The matching dictionary is released by IOServiceGetMatchingService, a
+	// main port of 0 is the default one.
+	service := C.IOServiceGetMatchingService(0, C.CFDictionaryRef(C.IOServiceMatching(className)))
+	if service == 0 {
+		return macPlatform{}, errNoPlatformExpert
+	}
+	defer C.IOObjectRelease(C.io_object_t(service))
+
+	entry := C.io_registry_entry_t(service)
+	return macPlatform{
+		serial:       registryEntryProperty(entry, "IOPlatformSerialNumber"),
+		manufacturer: registryEntryProperty(entry, "manufacturer"),
+		model:        registryEntryProperty(entry, "model"),
+		boardID:      registryEntryProperty(entry, "board-id"),
+	}, nil
+}
+
+// ioregistryVendorNames walks every entry of the IOService plane for the
+// vendor name properties.
+func ioregistryVendorNames() []string {
+	plane := C.CString("IOService")
+	defer C.free(unsafe.Pointer(plane))
+
+	var iterator C.io_iterator_t
+	if C.IORegistryCreateIterator(0, plane, C.kIORegistryIterateRecursively, &iterator) != 0 {
+		return nil
+	}
+	defer C.IOObjectRelease(C.io_object_t(iterator))
+
+	var values []string
+	for entry := C.IOIteratorNext(iterator); entry != 0; entry = C.IOIteratorNext(iterator) {
+		for _, property := range ioregistryVendorProperties {
+			if value := registryEntryProperty(C.io_registry_entry_t(entry), property); value != "" {
+				values = append(values, value)
+			}
+		}
+		C.IOObjectRelease(entry)
+	}
+
+	return values
+}
+
+// registryEntryProperty reads a string or data property of an entry as a
+// string, data properties like the manufacturer are NUL terminated.
+func registryEntryProperty(entry C.io_registry_entry_t, name string) string {
+	key := cfString(name)
+	if key == 0 {
+		return ""
+	}
+	defer C.CFRelease(C.CFTypeRef(key))
+
+	value := C.IORegistryEntryCreateCFProperty(entry, key, C.kCFAllocatorDefault, 0)
+	if value == 0 {
+		return ""
+	}
+	defer C.CFRelease(value)
+
+	switch C.CFGetTypeID(value) {
+	case C.CFStringGetTypeID():
+		return goString(C.CFStringRef(value))
+	case C.CFDataGetTypeID():
+		data := C.CFDataRef(value)
+		bytes := C.GoBytes(unsafe.Pointer(C.CFDataGetBytePtr(data)), C.int(C.CFDataGetLength(data)))
+		return strings.TrimSpace(strings.TrimRight(string(bytes), "\x00"))
+	}
+
+	return ""
+}
+
+func cfString(s string) C.CFStringRef {
+	cs := C.CString(s)
+	defer C.free(unsafe.Pointer(cs))
+
+	return C.CFStringCreateWithCString(C.kCFAllocatorDefault, cs, C.kCFStringEncodingUTF8)
+}
+
+func goString(s C.CFStringRef) string {
+	size := C.CFStringGetMaximumSizeForEncoding(C.CFStringGetLength(s), C.kCFStringEncodingUTF8) + 1
+	buf := make([]byte, size)
+	if C.CFStringGetCString(s, (*C.char)(unsafe.Pointer(&buf[0])), size, C.kCFStringEncodingUTF8) == 0 {
+		return ""
+	}
+
+	return strings.TrimSpace(C.GoString((*C.char)(unsafe.Pointer(&buf[0]))))
+}
@@ -0,0 +1,130 @@
+//go:build darwin && !cgo
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * mac_ioreg.go
+ * ---
+ * Last Modified: 20/10/2026 01:25PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"github.com/Inspect-Element-Ltd/vm/internal/util"
+	"strings"
+)
+
+// Without cgo there's no IOKit, so the IORegistry is read from ioreg's plist
+// output instead, which is structured unlike its default text output. That's
+// still a child process, which is what cross-compiled builds get by default.
+
+var errNoPlatformExpert = errors.New("IOPlatformExpertDevice not found")
+
+// platformExpert reads the IOPlatformExpertDevice entry through ioreg.
+func platformExpert() (macPlatform, error) {
+	output, err := util.InvokeCMD("ioreg", "-a", "-r", "-d", "1", "-c", "IOPlatformExpertDevice")
+	if err != nil {
+		return macPlatform{}, err
+	}
+
+	values := plistValues([]byte(output), []string{"IOPlatformSerialNumber", "manufacturer", "model", "board-id"})
+	if len(values) == 0 {
+		return macPlatform{}, errNoPlatformExpert
+	}
+
+	first := func(key string) string {
+		if len(values[key]) == 0 {
+			return ""
+		}
+		return values[key][0]
+	}
+
+	return macPlatform{
+		serial:       first("IOPlatformSerialNumber"),
+		manufacturer: first("manufacturer"),
+		model:        first("model"),
+		boardID:      first("board-id"),
+	}, nil
+}
+
+// ioregistryVendorNames lists the vendor name properties of every entry of the IOService plane.
+func ioregistryVendorNames() []string {
+	output, err := util.InvokeCMD("ioreg", "-a", "-l")
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	values := plistValues([]byte(output), ioregistryVendorProperties)
+	for _, property := range ioregistryVendorProperties {
+		names = append(names, values[property]...)
+	}
+
+	return names
+}
+
+// plistValues collects the string and data values of the given keys from an
+// XML plist, at any depth. Data is decoded and its NUL terminator dropped.
+func plistValues(plist []byte, keys []string) map[string][]string {
+	wanted := make(map[string]bool)
+	for _, key := range keys {
+		wanted[key] = true
+	}
+
+	values := make(map[string][]string)
+	decoder := xml.NewDecoder(bytes.NewReader(plist))
+	// ioreg's output has a DOCTYPE, which the decoder is fine with, but no entities.
+	decoder.Strict = false
+
+	key := ""
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "key":
+			var name string
+			if decoder.DecodeElement(&name, &start) == nil {
+				key = name
+			}
+			continue
+		case "string", "data":
+			var text string
+			if decoder.DecodeElement(&text, &start) != nil || !wanted[key] {
+				break
+			}
+
+			if start.Name.Local == "data" {
+				data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+				if err != nil {
+					break
+				}
+				text = strings.TrimRight(string(data), "\x00")
+			}
+
+			if text = strings.TrimSpace(text); text != "" {
+				values[key] = append(values[key], text)
+			}
+		}
+
+		// Any value ends the key it belonged to.
+		key = ""
+	}
+
+	return values
+}
@@ -0,0 +1,239 @@
+//go:build darwin
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * mac_net.go
+ * ---
+ * Last Modified: 20/10/2026 02:10PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"golang.org/x/sys/unix"
+	"net"
+	"unsafe"
+)
+
+// The routing table and ARP cache are read through the sysctl route and arp
+// use themselves, no binaries are run so sandboxed callers work too.
+
+// route is an entry of the routing table, with only what the checks use.
+type route struct {
+	index   int // Interface index.
+	flags   int
+	dst     net.IP
+	netmask net.IP
+	gateway net.IP
+	link    net.HardwareAddr // Link layer address of ARP entries.
+}
+
+// routeTable dumps the IPv4 routes that have all of flags set.
+func routeTable(flags int) []route {
+	buf, err := unix.SysctlRaw("net.routetable", 0, unix.AF_INET, unix.NET_RT_FLAGS, flags)
+	if err != nil {
+		return nil
+	}
+
+	return parseRoutes(buf)
+}
+
+// parseRoutes parses rt_msghdr messages, each is followed by the sockaddrs
+// its rtm_addrs bits say it carries.
+func parseRoutes(buf []byte) []route {
+	var routes []route
+	for len(buf) >= unix.SizeofRtMsghdr {
+		header := (*unix.RtMsghdr)(unsafe.Pointer(&buf[0]))
+		if int(header.Msglen) < unix.SizeofRtMsghdr || int(header.Msglen) > len(buf) {
+			break
+		}
+		message := buf[:header.Msglen]
+		buf = buf[header.Msglen:]
+
+		if header.Version != unix.RTM_VERSION {
+			continue
+		}
+
+		rt := route{index: int(header.Index), flags: int(header.Flags)}
+		addrs := message[unix.SizeofRtMsghdr:]
+		for i := 0; i < unix.RTAX_MAX && len(addrs) > 0; i++ {
+			if header.Addrs&(1<<i) == 0 {
+				continue
+			}
+
+			length := int(addrs[0])
+			if length > len(addrs) {
+				break
+			}
+
+			sa := addrs[:length]
+			switch i {
+			case unix.RTAX_DST:
+				rt.dst = sockaddrIPv4(sa)
+			case unix.RTAX_GATEWAY:
+				rt.gateway = sockaddrIPv4(sa)
+				rt.link = sockaddrLink(sa)
+			case unix.RTAX_NETMASK:
+				// Masks are cut short after their last set byte and may not have a family.
+				mask := make(net.IP, net.IPv4len)
+				if length > 4 {
+					copy(mask, sa[4:])
+				}
+				rt.netmask = mask
+			}
+
+			// Each sockaddr is padded to 4 bytes, an empty one still takes 4.
+			if length == 0 {
+				length = 4
+			}
+			length = (length + 3) &^ 3
+			if length > len(addrs) {
+				break
+			}
+			addrs = addrs[length:]
+		}
+
+		routes = append(routes, rt)
+	}
+
+	return routes
+}
+
+// sockaddrIPv4 returns the address of a sockaddr_in.
+func sockaddrIPv4(sa []byte) net.IP {
+	if len(sa) < 8 || sa[1] != unix.AF_INET {
+		return nil
+	}
+
+	return net.IPv4(sa[4], sa[5], sa[6], sa[7]).To4()
+}
+
+// sockaddrLink returns the link layer address of a sockaddr_dl, which comes
+// after the interface name in sdl_data.
+func sockaddrLink(sa []byte) net.HardwareAddr {
+	if len(sa) < 8 || sa[1] != unix.AF_LINK {
+		return nil
+	}
+
+	nameLength, addrLength := int(sa[5]), int(sa[6])
+	if addrLength == 0 || 8+nameLength+addrLength > len(sa) {
+		return nil
+	}
+
+	return net.HardwareAddr(append([]byte(nil), sa[8+nameLength:8+nameLength+addrLength]...))
+}
+
+// defaultRoute returns the unscoped default route, every interface can have
+// a scoped one of its own as well.
+func defaultRoute() (route, bool) {
+	var scoped *route
+	for _, rt := range routeTable(unix.RTF_GATEWAY) {
+		if rt.dst == nil || !rt.dst.IsUnspecified() || (rt.netmask != nil && !rt.netmask.IsUnspecified()) {
+			continue
+		}
+
+		if rt.flags&unix.RTF_IFSCOPE == 0 {
+			return rt, true
+		}
+		if scoped == nil {
+			scoped = &rt
+		}
+	}
+
+	if scoped != nil {
+		return *scoped, true
+	}
+
+	return route{}, false
+}
+
+func defaultRouteInterface() string {
+	rt, ok := defaultRoute()
+	if !ok {
+		return ""
+	}
+
+	iface, err := net.InterfaceByIndex(rt.index)
+	if err != nil {
+		return ""
+	}
+
+	return iface.Name
+}
+
+// defaultGateway reads the default route and looks the gateway up in the ARP cache.
+func defaultGateway() (gateway, bool) {
+	rt, ok := defaultRoute()
+	if !ok || rt.gateway == nil {
+		return gateway{}, false
+	}
+
+	iface, err := net.InterfaceByIndex(rt.index)
+	if err != nil {
+		return gateway{}, false
+	}
+
+	return gateway{iface: iface.Name, address: interfaceIPv4(iface.Name), ip: rt.gateway, mac: neighbour(rt.gateway)}, true
+}
+
+// neighbour looks up the MAC address of ip in the ARP cache without sending anything.
+func neighbour(ip net.IP) net.HardwareAddr {
+	for _, rt := range routeTable(unix.RTF_LLINFO) {
+		if rt.dst.Equal(ip) && rt.link != nil {
+			return rt.link
+		}
+	}
+
+	return nil
+}
+
+// readAdapterInfo reads what the host adapter check needs about iface, only
+// its name tells Fusion's vmnet8 or Parallels' vnic0 apart.
+func readAdapterInfo(iface net.Interface) adapterInfo {
+	return adapterInfo{name: iface.Name}
+}
+
+// nics isn't collected on macOS, the paravirtual drivers worth matching
+// only exist on Linux and Windows guests.
+func nics() []nic {
+	return nil
+}
+
+// Media types of ifm_current, from net/if_media.h.
+const (
+	ifmNetworkMask = 0xe0
+	ifmEthernet    = 0x20
+)
+
+// ifMediaReq is struct ifmediareq, which is packed to 4 bytes.
+type ifMediaReq struct {
+	name    [unix.IFNAMSIZ]byte
+	current int32
+	mask    int32
+	status  int32
+	active  int32
+	count   int32
+	ulist   [8]byte // int *, unaligned.
+}
+
+// isWired reports whether iface is Ethernet going by its media, Wi-Fi
+// reports its own media type even though it looks like Ethernet otherwise.
+func isWired(iface net.Interface) bool {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM, 0)
+	if err != nil {
+		return false
+	}
+	defer unix.Close(fd)
+
+	var req ifMediaReq
+	copy(req.name[:len(req.name)-1], iface.Name)
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), unix.SIOCGIFMEDIA, uintptr(unsafe.Pointer(&req))); errno != 0 {
+		return false
+	}
+
+	return req.current&ifmNetworkMask == ifmEthernet
+}
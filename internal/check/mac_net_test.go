@@ -0,0 +1,65 @@
+//go:build darwin
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * mac_net_test.go
+ * ---
+ * Last Modified: 20/10/2026 02:10PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"golang.org/x/sys/unix"
+	"net"
+	"testing"
+	"unsafe"
+)
+
+// routeMessage builds an rt_msghdr followed by its sockaddrs, which have to be padded already.
+func routeMessage(index uint16, flags int32, addrs int32, sockaddrs ...[]byte) []byte {
+	header := unix.RtMsghdr{Version: unix.RTM_VERSION, Type: unix.RTM_GET, Index: index, Flags: flags, Addrs: addrs}
+
+	message := append([]byte(nil), unsafe.Slice((*byte)(unsafe.Pointer(&header)), unix.SizeofRtMsghdr)...)
+	for _, sa := range sockaddrs {
+		message = append(message, sa...)
+	}
+	(*unix.RtMsghdr)(unsafe.Pointer(&message[0])).Msglen = uint16(len(message))
+
+	return message
+}
+
+func inet4(a, b, c, d byte) []byte {
+	return []byte{16, unix.AF_INET, 0, 0, a, b, c, d, 0, 0, 0, 0, 0, 0, 0, 0}
+}
+
+func TestParseRoutes(t *testing.T) {
+	link := []byte{20, unix.AF_LINK, 4, 0, 6, 0, 6, 0, 0x52, 0x54, 0x00, 0x12, 0x35, 0x02, 0, 0, 0, 0, 0, 0}
+
+	var buf []byte
+	buf = append(buf, routeMessage(4, unix.RTF_GATEWAY|unix.RTF_IFSCOPE, unix.RTA_DST|unix.RTA_GATEWAY|unix.RTA_NETMASK, inet4(0, 0, 0, 0), inet4(192, 168, 1, 1), []byte{0, 0, 0, 0})...)
+	buf = append(buf, routeMessage(4, unix.RTF_GATEWAY, unix.RTA_DST|unix.RTA_GATEWAY|unix.RTA_NETMASK, inet4(0, 0, 0, 0), inet4(10, 0, 2, 2), []byte{0, 0, 0, 0})...)
+	buf = append(buf, routeMessage(4, unix.RTF_LLINFO, unix.RTA_DST|unix.RTA_GATEWAY, inet4(10, 0, 2, 2), link)...)
+	// A truncated message is dropped.
+	buf = append(buf, routeMessage(4, unix.RTF_GATEWAY, unix.RTA_DST, inet4(0, 0, 0, 0))[:20]...)
+
+	routes := parseRoutes(buf)
+	if len(routes) != 3 {
+		t.Fatalf("got %d routes, expected 3: %+v", len(routes), routes)
+	}
+
+	if !routes[1].dst.IsUnspecified() || !routes[1].netmask.IsUnspecified() || !routes[1].gateway.Equal(net.IPv4(10, 0, 2, 2)) {
+		t.Errorf("default route is %+v", routes[1])
+	}
+	if routes[1].flags&unix.RTF_IFSCOPE != 0 || routes[0].flags&unix.RTF_IFSCOPE == 0 {
+		t.Errorf("scoped flags are wrong: %+v", routes)
+	}
+
+	if !routes[2].dst.Equal(net.IPv4(10, 0, 2, 2)) || routes[2].link.String() != "52:54:00:12:35:02" {
+		t.Errorf("ARP entry is %+v", routes[2])
+	}
+}
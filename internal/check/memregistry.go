@@ -0,0 +1,117 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * memregistry.go
+ * ---
+ * Last Modified: 20/10/2026 03:15PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	errRegistryKeyNotFound   = errors.New("registry key not found")
+	errRegistryValueNotFound = errors.New("registry value not found")
+)
+
+// memoryRegistry is a registry held in memory, keyed by the lowercased path
+// of every key. It's what snapshots are replayed against.
+type memoryRegistry map[string]*memoryKey
+
+type memoryKey struct {
+	subKeys []string
+	values  map[string]memoryValue // Lowercased name -> value.
+}
+
+type memoryValue struct {
+	name  string
+	value registryValue
+}
+
+// snapshotRegistry builds a registry from the keys and values in a snapshot.
+func snapshotRegistry(snap *Snapshot) memoryRegistry {
+	reg := make(memoryRegistry)
+
+	for _, key := range snap.RegistryKeys {
+		reg.addKey(key)
+	}
+
+	for registryPath, data := range snap.RegistryValues {
+		segments := splitRegistryPath(registryPath)
+		if len(segments) < 2 {
+			continue
+		}
+
+		name := segments[len(segments)-1]
+		key := reg.addKey(strings.Join(segments[:len(segments)-1], `\`))
+		key.values[strings.ToLower(name)] = memoryValue{name: name, value: snapshotValue(data, snap.RegistryTypes[registryPath])}
+	}
+
+	return reg
+}
+
+// addKey adds a key and every key above it.
+func (reg memoryRegistry) addKey(registryPath string) *memoryKey {
+	var parent *memoryKey
+	var keyPath string
+
+	for _, segment := range splitRegistryPath(registryPath) {
+		keyPath = joinRegistryPath(keyPath, segment)
+
+		key, ok := reg[strings.ToLower(keyPath)]
+		if !ok {
+			key = &memoryKey{values: make(map[string]memoryValue)}
+			reg[strings.ToLower(keyPath)] = key
+
+			if parent != nil {
+				parent.subKeys = append(parent.subKeys, segment)
+			}
+		}
+
+		parent = key
+	}
+
+	return parent
+}
+
+func (reg memoryRegistry) OpenKey(registryPath string) (registryKey, error) {
+	key, ok := reg[strings.ToLower(strings.Join(splitRegistryPath(registryPath), `\`))]
+	if !ok {
+		return nil, errRegistryKeyNotFound
+	}
+
+	return key, nil
+}
+
+func (k *memoryKey) SubKeyNames() ([]string, error) {
+	return k.subKeys, nil
+}
+
+func (k *memoryKey) ValueNames() ([]string, error) {
+	names := make([]string, 0, len(k.values))
+	for _, value := range k.values {
+		names = append(names, value.name)
+	}
+
+	return names, nil
+}
+
+func (k *memoryKey) Value(name string) (registryValue, error) {
+	value, ok := k.values[strings.ToLower(name)]
+	if !ok {
+		return registryValue{}, errRegistryValueNotFound
+	}
+
+	return value.value, nil
+}
+
+func (k *memoryKey) Close() error {
+	return nil
+}
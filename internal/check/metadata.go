@@ -0,0 +1,110 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * metadata.go
+ * ---
+ * Last Modified: 20/10/2026 01:20PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"maps"
+	"strings"
+	"sync/atomic"
+)
+
+// Keys of Evidence.Metadata.
+const (
+	MetadataHostName     = "host_name"
+	MetadataVMName       = "vm_name"
+	MetadataVMID         = "vm_id"
+	MetadataToolsVersion = "tools_version"
+)
+
+// Hyper-V's KVP data exchange copies the host's details into the guest here.
+const hyperVGuestParameters = `HKLM\SOFTWARE\Microsoft\Virtual Machine\Guest\Parameters`
+
+var hyperVParameters = map[string]string{
+	"HostName":           MetadataHostName,
+	"VirtualMachineName": MetadataVMName,
+	"VirtualMachineId":   MetadataVMID,
+}
+
+// Display names guest tools install under, their version is read from the uninstall entry.
+var guestToolsProducts = map[string]string{
+	"VMware":     "VMware Tools",
+	"VirtualBox": "VirtualBox Guest Additions",
+}
+
+const uninstallKey = `HKLM\SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`
+
+var collectMetadata atomic.Bool
+
+// CollectGuestMetadata turns collecting guest metadata on or off, it's off by
+// default. The host name and VM name identify the host and whoever runs it,
+// only turn it on when that's allowed to be collected.
+func CollectGuestMetadata(enabled bool) {
+	collectMetadata.Store(enabled)
+}
+
+// guestMetadata reads the metadata of every vendor the registry holds any for.
+func guestMetadata(reg registryReader) map[string]map[string]string {
+	metadata := make(map[string]map[string]string)
+
+	for _, name := range vendorNames(hyperVParameters) {
+		if value, ok := registryString(reg, joinRegistryPath(hyperVGuestParameters, name)); ok && value != "" {
+			addMetadata(metadata, "Hyper-V", hyperVParameters[name], value)
+		}
+	}
+
+	keyHandle, err := reg.OpenKey(uninstallKey)
+	if err != nil {
+		return metadata
+	}
+	products, _ := keyHandle.SubKeyNames()
+	keyHandle.Close()
+
+	for _, product := range products {
+		productKey := joinRegistryPath(uninstallKey, product)
+		displayName, _ := registryString(reg, joinRegistryPath(productKey, "DisplayName"))
+		version, ok := registryString(reg, joinRegistryPath(productKey, "DisplayVersion"))
+		if !ok || version == "" {
+			continue
+		}
+
+		for _, vendor := range vendorNames(guestToolsProducts) {
+			if strings.Contains(strings.ToLower(displayName), strings.ToLower(guestToolsProducts[vendor])) {
+				addMetadata(metadata, vendor, MetadataToolsVersion, version)
+			}
+		}
+	}
+
+	return metadata
+}
+
+func addMetadata(metadata map[string]map[string]string, vendor string, field string, value string) {
+	if metadata[vendor] == nil {
+		metadata[vendor] = make(map[string]string)
+	}
+	metadata[vendor][field] = value
+}
+
+// attachGuestMetadata attaches the metadata in reg to the VM evidence found
+// from start on, if collecting it is turned on.
+func attachGuestMetadata(reg registryReader, r *Report, start int) {
+	if !collectMetadata.Load() || start >= len(r.Evidence) {
+		return
+	}
+
+	metadata := guestMetadata(reg)
+	for i := start; i < len(r.Evidence); i++ {
+		if fields, ok := metadata[r.Evidence[i].Vendor]; ok && r.Evidence[i].Class == ClassVM {
+			// Each entry gets its own copy, so changing one doesn't change the others.
+			r.Evidence[i].Metadata = maps.Clone(fields)
+		}
+	}
+}
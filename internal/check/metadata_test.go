@@ -0,0 +1,43 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * metadata_test.go
+ * ---
+ * Last Modified: 20/10/2026 01:20PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import "testing"
+
+func TestAttachGuestMetadata(t *testing.T) {
+	CollectGuestMetadata(true)
+	t.Cleanup(func() { CollectGuestMetadata(false) })
+
+	reg := snapshotRegistry(&Snapshot{RegistryValues: map[string]string{
+		hyperVGuestParameters + `\HostName`: "host.example",
+	}})
+
+	r := &Report{}
+	r.Add(Evidence{Class: ClassVM, Vendor: "Hyper-V", Reason: "first"})
+	r.Add(Evidence{Class: ClassVM, Vendor: "Hyper-V", Reason: "second"})
+	r.Add(Evidence{Class: ClassHypervisorInstalled, Vendor: "Hyper-V", Reason: "host"})
+	attachGuestMetadata(reg, r, 0)
+
+	for _, e := range r.Evidence[:2] {
+		if e.Metadata[MetadataHostName] != "host.example" {
+			t.Fatalf("%s has metadata %v", e.Reason, e.Metadata)
+		}
+	}
+	if r.Evidence[2].Metadata != nil {
+		t.Errorf("%s has metadata %v", r.Evidence[2].Reason, r.Evidence[2].Metadata)
+	}
+
+	r.Evidence[0].Metadata[MetadataHostName] = "changed"
+	if value := r.Evidence[1].Metadata[MetadataHostName]; value != "host.example" {
+		t.Errorf("changing the first entry's metadata changed the second's to %s", value)
+	}
+}
@@ -5,41 +5,240 @@
  *
  * net.go
  * ---
- * Last Modified: 11/05/2024 12:22AM (BST)
+ * Last Modified: 20/10/2026 02:20PM (BST)
  * Modified By: Gianluca Oliva <hello@gian.sh>
  */
 
 package check
 
 import (
+	"bytes"
+	"fmt"
 	"net"
 	"strings"
 )
 
-var (
-	ouiByVendor = map[string][]string{
-		"Parallels": {
-			"00:1C:42",
-		},
-		"VMware": {
-			"00:05:69", "00:0C:29", "00:1C:14", "00:50:56",
-		},
+// nic is the inventory of a single network interface.
+type nic struct {
+	name      string
+	driver    string // Kernel driver or Windows service bound to the device.
+	bus       string // e.g. pci, virtio, vmbus or xen.
+	id        string // PCI vendor:device, if on the PCI bus.
+	subsystem string // PCI subsystem vendor:device, if on the PCI bus.
+}
+
+func (n nic) String() string {
+	parts := []string{n.name}
+	if n.driver != "" {
+		parts = append(parts, "driver "+n.driver)
 	}
-)
+	if n.bus != "" {
+		parts = append(parts, "bus "+n.bus)
+	}
+	if n.id != "" {
+		parts = append(parts, "PCI "+n.id)
+	}
+	if n.subsystem != "" {
+		parts = append(parts, "subsystem "+n.subsystem)
+	}
+
+	return strings.Join(parts, ", ")
+}
 
-func MACAddress() (bool, string, string) {
+// ouiEntry maps a MAC address prefix to the vendor of the virtual NIC.
+type ouiEntry struct {
+	prefix []byte
+	vendor string
+}
+
+// ouiVendor returns the vendor whose prefix addr starts with.
+//
+// Addresses are compared as bytes so the case of the hex doesn't matter.
+func (s *signatureSet) ouiVendor(addr net.HardwareAddr) (string, bool) {
+	for _, entry := range s.ouis {
+		if bytes.HasPrefix(addr, entry.prefix) {
+			return entry.vendor, true
+		}
+	}
+
+	return "", false
+}
+
+// MACAddress checks the MAC address of every interface against the OUI table.
+//
+// Adapters hypervisors create on the host, like VMware's vmnet8, VirtualBox's
+// host-only adapters or a Hyper-V virtual switch, carry the same prefixes as
+// their guests. Those are only reported as the hypervisor being installed.
+func MACAddress(r *Report) {
+	primary := defaultRouteInterface()
 
 	if ifaces, err := net.Interfaces(); err == nil && ifaces != nil {
 		for _, iface := range ifaces {
-			for vendor, ouis := range ouiByVendor {
-				for _, oui := range ouis {
-					if strings.HasPrefix(iface.HardwareAddr.String(), oui) {
-						return true, vendor, "OUI Prefix matches " + vendor
-					}
-				}
+			if iface.Flags&net.FlagLoopback != 0 {
+				continue
+			}
+
+			vendor, ok := sigs().ouiVendor(iface.HardwareAddr)
+			if !ok {
+				continue
+			}
+
+			if why, host := sigs().hostAdapter(readAdapterInfo(iface), iface.Name == primary); host {
+				r.Add(Evidence{
+					Class:  ClassHypervisorInstalled,
+					Vendor: vendor,
+					Reason: fmt.Sprintf("%s (%s) is a host adapter, %s", iface.Name, iface.HardwareAddr, why),
+				})
+				continue
+			}
+
+			r.Add(Evidence{
+				Class:  ClassVM,
+				Vendor: vendor,
+				Reason: "OUI Prefix matches " + vendor,
+				Observations: []Observation{
+					{Source: "MAC", Value: iface.HardwareAddr.String(), Vendor: vendor, Virtual: true},
+				},
+			})
+		}
+	}
+
+	primaryMACAnomaly(r, primary)
+}
+
+// primaryMACAnomaly checks whether the wired adapter carrying the default route
+// has a MAC address that can't have come from a manufacturer, which is how a
+// VMware or QEMU OUI usually gets hidden.
+//
+// Wi-Fi adapters randomise their address for privacy so they're skipped.
+func primaryMACAnomaly(r *Report, primary string) {
+	iface, err := net.InterfaceByName(primary)
+	if err != nil || len(iface.HardwareAddr) == 0 || !isWired(*iface) {
+		return
+	}
+
+	if _, ok := sigs().ouiVendor(iface.HardwareAddr); ok {
+		return
+	}
+
+	if anomaly := macAnomaly(iface.HardwareAddr); anomaly != "" {
+		r.Add(Evidence{
+			Class:  ClassCloaking,
+			Reason: fmt.Sprintf("Primary adapter %s has %s MAC %s", iface.Name, anomaly, iface.HardwareAddr),
+			Observations: []Observation{
+				{Source: "MAC", Value: iface.HardwareAddr.String(), Spoofed: true},
+			},
+			Weak: true,
+		})
+	}
+}
+
+// macAnomaly describes why addr can't be a burned-in address, if it can't.
+func macAnomaly(addr net.HardwareAddr) string {
+	zero := true
+	for _, b := range addr {
+		if b != 0 {
+			zero = false
+			break
+		}
+	}
+
+	switch {
+	case zero:
+		return "an all-zero"
+	case addr[0]&0x01 != 0:
+		return "a multicast"
+	case addr[0]&0x02 != 0:
+		return "a locally administered"
+	default:
+		return ""
+	}
+}
+
+// adapterInfo is what the OS says about an interface that could have been
+// created by a hypervisor on the host.
+type adapterInfo struct {
+	name        string
+	description string // Windows only.
+	software    string // Why the OS says it's a software adapter, e.g. a tunnel.
+	unbound     bool   // Not bound to a driver for any (virtual) device.
+}
+
+// hostAdapter reports whether an adapter was created by a hypervisor on the
+// host. Its name, description or type say so whether or not it carries the
+// default route, a Hyper-V host's external switch does. Not being bound to a
+// device only counts off the default route, a guest's NIC can sit behind a
+// bridge that takes its MAC.
+func (s *signatureSet) hostAdapter(info adapterInfo, primary bool) (string, bool) {
+	for _, vendor := range vendorNames(s.hostAdapterNames) {
+		for _, prefix := range s.hostAdapterNames[vendor] {
+			if strings.HasPrefix(strings.ToLower(info.name), prefix) {
+				return fmt.Sprintf("named like a %s host adapter", vendor), true
 			}
 		}
 	}
 
-	return false, "", ""
+	for _, vendor := range vendorNames(s.hostAdapterDescriptions) {
+		for _, prefix := range s.hostAdapterDescriptions[vendor] {
+			if info.description != "" && strings.HasPrefix(info.description, prefix) {
+				return fmt.Sprintf("%s adapter %s", vendor, info.description), true
+			}
+		}
+	}
+
+	if info.software != "" {
+		return info.software, true
+	}
+
+	if info.unbound && !primary {
+		return "not bound to a device driver", true
+	}
+
+	return "", false
+}
+
+// NetworkInterfaces checks the driver, bus and PCI IDs behind every interface.
+//
+// Emulated NICs like QEMU's e1000 look like real Intel hardware until the
+// PCI subsystem ID, which QEMU sets to its own, is checked.
+func NetworkInterfaces(r *Report) {
+	for _, n := range nics() {
+		vendor, why := sigs().nicVendor(n)
+		if vendor == "" {
+			continue
+		}
+
+		r.Add(Evidence{
+			Class:  ClassVM,
+			Vendor: vendor,
+			Reason: fmt.Sprintf("%s %s", n.name, why),
+			Observations: []Observation{
+				{Source: "NIC", Value: n.String(), Vendor: vendor, Virtual: true},
+			},
+		})
+	}
+}
+
+func (s *signatureSet) nicVendor(n nic) (string, string) {
+	for _, vendor := range vendorNames(s.nicDrivers) {
+		for _, driver := range s.nicDrivers[vendor] {
+			if strings.EqualFold(n.driver, driver) {
+				return vendor, fmt.Sprintf("uses the %s driver", n.driver)
+			}
+		}
+	}
+
+	if vendor, ok := s.nicBuses[strings.ToLower(n.bus)]; ok {
+		return vendor, fmt.Sprintf("sits on the %s bus", n.bus)
+	}
+
+	if vendor, ok := s.pciVendors[pciVendorID(n.id)]; ok {
+		return vendor, fmt.Sprintf("is PCI device %s", n.id)
+	}
+
+	if vendor, ok := s.pciVendors[pciVendorID(n.subsystem)]; ok {
+		return vendor, fmt.Sprintf("is PCI device %s with %s subsystem %s", n.id, vendor, n.subsystem)
+	}
+
+	return "", ""
 }
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * net_test.go
+ * ---
+ * Last Modified: 20/10/2026 02:20PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"testing"
+)
+
+func TestHostAdapter(t *testing.T) {
+	s := compile(testSignatures(t, map[string]signature.Vendor{
+		"Hyper-V": {HostAdapterDescriptions: []string{"Hyper-V Virtual Ethernet Adapter"}},
+		"VMware":  {HostAdapterNames: []string{"vmnet"}, HostAdapterDescriptions: []string{"VMware Virtual Ethernet Adapter"}},
+	}))
+
+	tests := []struct {
+		name    string
+		info    adapterInfo
+		primary bool
+		host    bool
+	}{
+		{
+			name:    "primary Hyper-V virtual switch",
+			info:    adapterInfo{name: "vEthernet (External)", description: "Hyper-V Virtual Ethernet Adapter"},
+			primary: true,
+			host:    true,
+		},
+		{
+			name: "Hyper-V virtual switch",
+			info: adapterInfo{name: "vEthernet (Default Switch)", description: "Hyper-V Virtual Ethernet Adapter #2"},
+			host: true,
+		},
+		{
+			name:    "Hyper-V guest NIC",
+			info:    adapterInfo{name: "Ethernet", description: "Microsoft Hyper-V Network Adapter"},
+			primary: true,
+		},
+		{
+			name:    "primary vmnet",
+			info:    adapterInfo{name: "vmnet8"},
+			primary: true,
+			host:    true,
+		},
+		{
+			name:    "primary tunnel",
+			info:    adapterInfo{name: "tun0", software: "adapter type 131"},
+			primary: true,
+			host:    true,
+		},
+		{
+			name: "unbound",
+			info: adapterInfo{name: "br0", unbound: true},
+			host: true,
+		},
+		{
+			name:    "primary unbound bridge",
+			info:    adapterInfo{name: "br0", unbound: true},
+			primary: true,
+		},
+		{
+			name:    "guest NIC",
+			info:    adapterInfo{name: "ens18"},
+			primary: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if why, host := s.hostAdapter(test.info, test.primary); host != test.host {
+				t.Errorf("host is %v (%s), expected %v", host, why, test.host)
+			}
+		})
+	}
+}
+
+func TestNICVendor(t *testing.T) {
+	// Drivers aren't owned by a single vendor, the first by name wins.
+	s := compile(testSignatures(t, map[string]signature.Vendor{
+		"Red Hat": {NICDrivers: []string{"virtio_net"}, PCIVendors: []string{"1af4"}},
+		"QEMU":    {NICDrivers: []string{"virtio_net"}, NICBuses: []string{"virtio"}},
+		"Xen":     {NICBuses: []string{"xen"}},
+	}))
+
+	tests := []struct {
+		nic    nic
+		vendor string
+	}{
+		{nic: nic{name: "eth0", driver: "virtio_net", bus: "virtio"}, vendor: "QEMU"},
+		{nic: nic{name: "eth0", driver: "xen-netfront", bus: "xen"}, vendor: "Xen"},
+		{nic: nic{name: "eth0", driver: "e1000", bus: "pci", id: "8086:100e", subsystem: "1af4:1100"}, vendor: "Red Hat"},
+		{nic: nic{name: "eth0", driver: "e1000e", bus: "pci", id: "8086:15b8", subsystem: "1043:8672"}},
+	}
+
+	for _, test := range tests {
+		if vendor, why := s.nicVendor(test.nic); vendor != test.vendor {
+			t.Errorf("%s is %q (%s), expected %q", test.nic, vendor, why, test.vendor)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * netenv.go
+ * ---
+ * Last Modified: 19/10/2026 02:36PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"net"
+)
+
+// natDefault describes the network a hypervisor hands its guests by default,
+// every field that is set has to match. See signature.NATDefault.
+type natDefault struct {
+	vendor        string
+	name          string
+	network       string // CIDR the guest's address is in.
+	gateway       string // Exact gateway address.
+	gatewayHost   byte   // Last octet of the gateway address.
+	gatewayVendor string // Vendor of the gateway's MAC address.
+}
+
+// gateway is the default route as seen from the guest.
+type gateway struct {
+	iface   string
+	address net.IP // The interface's own IPv4 address.
+	ip      net.IP
+	mac     net.HardwareAddr
+}
+
+// NetworkEnvironment compares the default gateway with the NAT networks
+// hypervisors set up by default.
+//
+// Plenty of physical machines sit behind a router running in a VM, so
+// everything found here is weak evidence.
+func NetworkEnvironment(r *Report) {
+	gw, ok := defaultGateway()
+	if !ok {
+		return
+	}
+
+	macVendor, _ := sigs().ouiVendor(gw.mac)
+
+	for _, nat := range sigs().natDefaults {
+		if !nat.matches(gw, macVendor) {
+			continue
+		}
+
+		r.Add(Evidence{
+			Class:  ClassVM,
+			Vendor: nat.vendor,
+			Reason: fmt.Sprintf("%s (%s) with gateway %s matches the %s", gw.iface, gw.address, gw.ip, nat.name),
+			Weak:   true,
+		})
+		return
+	}
+
+	if macVendor != "" {
+		r.Add(Evidence{
+			Class:  ClassVM,
+			Vendor: macVendor,
+			Reason: fmt.Sprintf("Gateway %s MAC %s belongs to %s", gw.ip, gw.mac, macVendor),
+			Weak:   true,
+		})
+	}
+}
+
+func (nat natDefault) matches(gw gateway, macVendor string) bool {
+	if nat.network != "" {
+		_, network, err := net.ParseCIDR(nat.network)
+		if err != nil || gw.address == nil || !network.Contains(gw.address) {
+			return false
+		}
+	}
+
+	if nat.gateway != "" && !gw.ip.Equal(net.ParseIP(nat.gateway)) {
+		return false
+	}
+
+	if nat.gatewayHost != 0 {
+		ip := gw.ip.To4()
+		if ip == nil || ip[3] != nat.gatewayHost {
+			return false
+		}
+	}
+
+	if nat.gatewayVendor != "" && nat.gatewayVendor != macVendor {
+		return false
+	}
+
+	return true
+}
+
+// interfaceIPv4 returns the first IPv4 address assigned to the named interface.
+func interfaceIPv4(name string) net.IP {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil
+	}
+
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+			return ipNet.IP.To4()
+		}
+	}
+
+	return nil
+}
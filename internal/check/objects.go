@@ -0,0 +1,46 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * objects.go
+ * ---
+ * Last Modified: 20/10/2026 01:20AM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Prefixes of the device and pipe names in the signatures.
+const (
+	devicePrefix = `\\.\`
+	pipePrefix   = `\\.\pipe\`
+)
+
+// matchDeviceObjects checks for the devices and pipes guest tools create,
+// exists reports whether one of them is there.
+func (s *signatureSet) matchDeviceObjects(exists func(object string) bool, r *Report) {
+	for _, vendor := range vendorNames(s.deviceObjects) {
+		for _, object := range s.deviceObjects[vendor] {
+			if !exists(object) {
+				continue
+			}
+
+			kind := "Device"
+			if isPipe(object) {
+				kind = "Named pipe"
+			}
+
+			r.Add(Evidence{Class: ClassVM, Vendor: vendor, Reason: fmt.Sprintf("%s %s exists", kind, object)})
+		}
+	}
+}
+
+func isPipe(object string) bool {
+	return len(object) > len(pipePrefix) && strings.EqualFold(object[:len(pipePrefix)], pipePrefix)
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * offline.go
+ * ---
+ * Last Modified: 20/10/2026 03:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"github.com/Inspect-Element-Ltd/vm/internal/regf"
+	"io/fs"
+)
+
+// Where Windows keeps the machine hives, relative to the system drive.
+var rootHives = []string{
+	"Windows/System32/config/SYSTEM",
+	"Windows/System32/config/SOFTWARE",
+}
+
+// OfflineRoot runs the file based checks against the root of another system,
+// e.g. a mounted Windows partition or an extracted Linux or macOS image.
+//
+// That's the file signatures, the Windows machine hives and the DMI, ACPI and
+// PCI entries of a sysfs copy. Every piece of evidence found records name as its root.
+func OfflineRoot(r *Report, name string, fsys fs.FS) {
+	s := sigs()
+	start := len(r.Evidence)
+
+	s.matchRootFiles(fsys, r)
+
+	hives := make(map[string]*regf.Hive)
+	for _, hivePath := range rootHives {
+		found, ok := findFold(fsys, hivePath)
+		if !ok {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, found)
+		if err != nil {
+			continue
+		}
+
+		if hive, err := regf.Parse(data); err == nil {
+			mount, _ := HiveMount(hivePath)
+			hives[mount] = hive
+		}
+	}
+	if reg, err := newHiveRegistry(hives); err == nil && len(hives) > 0 {
+		s.matchRegistry(reg, r)
+		s.matchServices(s.registryServices(reg), r)
+		s.matchDevices(registryDevices(reg), r)
+		matchSandbox(reg, "", nil, r)
+		s.matchWine(reg, wineState{}, r)
+	}
+
+	s.matchDMI(fsys, r)
+	s.matchACPITables(readACPITables(fsys), r)
+	s.matchPCI(readPCIDevices(fsys), r)
+
+	for i := start; i < len(r.Evidence); i++ {
+		r.Evidence[i].Root = name
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * pci.go
+ * ---
+ * Last Modified: 19/10/2026 09:35PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"github.com/Inspect-Element-Ltd/vm/internal/util"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Where sysfs lists PCI devices, relative to the root so it can be read from an offline root too.
+const sysPCIDevices = "sys/bus/pci/devices"
+
+type pciDevice struct {
+	address string
+	id      string // vendor:device
+	class   string
+	parent  string // id of the bridge the device sits behind, if any
+}
+
+// matchPCI checks the vendor IDs of PCI devices.
+func (s *signatureSet) matchPCI(devices []pciDevice, r *Report) {
+	for _, device := range devices {
+		if vendor, ok := s.pciVendors[pciVendorID(device.id)]; ok {
+			r.Add(Evidence{
+				Class:  ClassVM,
+				Vendor: vendor,
+				Reason: fmt.Sprintf("PCI device %s (%s) belongs to %s", device.address, device.id, vendor),
+				Observations: []Observation{
+					{Source: "PCI", Value: device.id, Vendor: vendor, Virtual: true},
+				},
+			})
+		}
+	}
+}
+
+// readPCIDevices lists the PCI devices in sysfs, parents are left empty
+// since finding them means resolving symlinks.
+func readPCIDevices(fsys fs.FS) []pciDevice {
+	entries, err := fs.ReadDir(fsys, sysPCIDevices)
+	if err != nil {
+		return nil
+	}
+
+	devices := make([]pciDevice, 0, len(entries))
+	for _, entry := range entries {
+		dir := path.Join(sysPCIDevices, entry.Name())
+
+		id, ok := readPCIID(fsys, dir)
+		if !ok {
+			continue
+		}
+
+		device := pciDevice{address: entry.Name(), id: id}
+		if class, err := util.ReadFSString(fsys, path.Join(dir, "class")); err == nil {
+			device.class = strings.TrimPrefix(class, "0x")
+		}
+
+		devices = append(devices, device)
+	}
+
+	return devices
+}
+
+// readPCIID reads the vendor:device pair of the PCI device at dir.
+func readPCIID(fsys fs.FS, dir string) (string, bool) {
+	return readPCIPair(fsys, dir, "vendor", "device")
+}
+
+// readPCISubsystemID reads the subsystem vendor:device pair of the PCI device at dir.
+func readPCISubsystemID(fsys fs.FS, dir string) string {
+	id, _ := readPCIPair(fsys, dir, "subsystem_vendor", "subsystem_device")
+	return id
+}
+
+func readPCIPair(fsys fs.FS, dir string, vendorFile string, deviceFile string) (string, bool) {
+	vendorID, err := util.ReadFSString(fsys, path.Join(dir, vendorFile))
+	if err != nil {
+		return "", false
+	}
+
+	deviceID, err := util.ReadFSString(fsys, path.Join(dir, deviceFile))
+	if err != nil {
+		return "", false
+	}
+
+	vendorID = strings.TrimPrefix(strings.ToLower(vendorID), "0x")
+	deviceID = strings.TrimPrefix(strings.ToLower(deviceID), "0x")
+
+	return vendorID + ":" + deviceID, true
+}
+
+// pciVendorID returns the vendor half of a vendor:device pair.
+func pciVendorID(id string) string {
+	vendorID, _, _ := strings.Cut(id, ":")
+	return vendorID
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * regfile.go
+ * ---
+ * Last Modified: 20/10/2026 03:15PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Full hive names used by regedit exports.
+var hiveNames = map[string]string{
+	"HKEY_LOCAL_MACHINE":  "HKLM",
+	"HKEY_CURRENT_USER":   "HKCU",
+	"HKEY_CLASSES_ROOT":   "HKCR",
+	"HKEY_USERS":          "HKU",
+	"HKEY_CURRENT_CONFIG": "HKCC",
+}
+
+// ParseRegFile reads a regedit export (.reg) into a snapshot of its keys and values,
+// so registry signatures can be tested against an exported machine.
+//
+// Deletions and default values are skipped, signatures can't refer to either.
+func ParseRegFile(name string, r io.Reader) (*Snapshot, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// regedit writes UTF-16LE with a BOM.
+	if bytes.HasPrefix(data, []byte{0xff, 0xfe}) {
+		data = []byte(strings.Join(utf16Strings(data[2:]), ""))
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	snap := &Snapshot{Name: name, RegistryValues: make(map[string]string), RegistryTypes: make(map[string]string)}
+
+	var key string
+	var lineNumber int
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Long hex values are wrapped with a trailing backslash.
+		for strings.HasSuffix(line, `\`) && scanner.Scan() {
+			lineNumber++
+			line = strings.TrimSuffix(line, `\`) + strings.TrimSpace(scanner.Text())
+		}
+
+		switch {
+		case line == "", strings.HasPrefix(line, ";"), line == "REGEDIT4", strings.HasPrefix(line, "Windows Registry Editor"):
+			continue
+		case strings.HasPrefix(line, "[-"):
+			key = ""
+		case strings.HasPrefix(line, "["):
+			key = regFileKey(strings.Trim(line, "[]"))
+			snap.RegistryKeys = append(snap.RegistryKeys, key)
+		case strings.HasPrefix(line, `"`):
+			if key == "" {
+				continue
+			}
+
+			valueName, data, valueType, err := regFileValue(line)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", name, lineNumber, err)
+			}
+			if data != nil {
+				snap.RegistryValues[key+`\`+valueName] = *data
+			}
+			if valueType != "" {
+				snap.RegistryTypes[key+`\`+valueName] = valueType
+			}
+		case strings.HasPrefix(line, "@="):
+			continue
+		default:
+			return nil, fmt.Errorf("%s:%d: unexpected line %q", name, lineNumber, line)
+		}
+	}
+
+	return snap, scanner.Err()
+}
+
+// regFileKey shortens the hive of a key to the form signatures use.
+func regFileKey(registryPath string) string {
+	hive, rest, _ := strings.Cut(registryPath, `\`)
+	if short, ok := hiveNames[strings.ToUpper(hive)]; ok {
+		hive = short
+	}
+
+	return joinRegistryPath(hive, rest)
+}
+
+// regFileValue parses a "name"=data line, the data and type are returned in
+// the form Snapshot.RegistryValues and RegistryTypes store them in. Deleted
+// values return nil data.
+func regFileValue(line string) (string, *string, string, error) {
+	valueName, rest, err := regFileString(line)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	rest, ok := strings.CutPrefix(rest, "=")
+	if !ok {
+		return "", nil, "", fmt.Errorf("value %q has no data", valueName)
+	}
+
+	var value registryValue
+	var valueType string
+	switch {
+	case rest == "-":
+		return valueName, nil, "", nil
+	case strings.HasPrefix(rest, `"`):
+		data, _, err := regFileString(rest)
+		if err != nil {
+			return "", nil, "", err
+		}
+		value = stringValue(data)
+	case strings.HasPrefix(rest, "dword:"):
+		n, err := strconv.ParseUint(strings.TrimPrefix(rest, "dword:"), 16, 32)
+		if err != nil {
+			return "", nil, "", fmt.Errorf("value %q: invalid dword", valueName)
+		}
+		value, valueType = integerValue(n), snapshotDWORD
+	case strings.HasPrefix(rest, "hex"):
+		hexType, data, err := regFileHex(rest)
+		if err != nil {
+			return "", nil, "", fmt.Errorf("value %q: %w", valueName, err)
+		}
+		value = decodeRegistryValue(hexType, data)
+		if value.numeric {
+			valueType = snapshotDWORD
+			if hexType == regQWORD {
+				valueType = snapshotQWORD
+			}
+		}
+	default:
+		return "", nil, "", fmt.Errorf("value %q has unknown data %q", valueName, rest)
+	}
+
+	data := strings.Join(value.strings, "\n")
+	if value.numeric {
+		data = strconv.FormatUint(value.integer, 10)
+	}
+
+	return valueName, &data, valueType, nil
+}
+
+// regFileString reads a quoted string from the start of s, returning it unescaped with the rest of s.
+func regFileString(s string) (string, string, error) {
+	var value strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+			if i < len(s) {
+				value.WriteByte(s[i])
+			}
+		case '"':
+			return value.String(), s[i+1:], nil
+		default:
+			value.WriteByte(s[i])
+		}
+	}
+
+	return "", "", fmt.Errorf("unterminated string %q", s)
+}
+
+// regFileHex parses hex:aa,bb or hex(7):aa,bb data.
+func regFileHex(s string) (uint32, []byte, error) {
+	prefix, rest, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, nil, errors.New("invalid hex data")
+	}
+
+	valueType := uint64(regBinary)
+	if typeName, found := strings.CutPrefix(prefix, "hex("); found {
+		n, err := strconv.ParseUint(strings.TrimSuffix(typeName, ")"), 16, 32)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid value type %q", prefix)
+		}
+		valueType = n
+	}
+
+	data, err := hex.DecodeString(strings.ReplaceAll(rest, ",", ""))
+	if err != nil {
+		return 0, nil, errors.New("invalid hex data")
+	}
+
+	return uint32(valueType), data, nil
+}
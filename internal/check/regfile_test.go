@@ -0,0 +1,120 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * regfile_test.go
+ * ---
+ * Last Modified: 20/10/2026 03:15PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"os"
+	"strings"
+	"testing"
+)
+
+func parseTestRegFile(t *testing.T) *Snapshot {
+	t.Helper()
+
+	f, err := os.Open("testdata/machine.reg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	snap, err := ParseRegFile("machine.reg", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return snap
+}
+
+func TestParseRegFile(t *testing.T) {
+	snap := parseTestRegFile(t)
+
+	values := map[string]string{
+		`HKLM\SOFTWARE\Oracle\VirtualBox Guest Additions\Version`: "7.0.14",
+		`HKLM\SOFTWARE\Test\Level`:                                "3",
+		`HKLM\SOFTWARE\Test\Limit`:                                "16",
+		`HKLM\SOFTWARE\Test\Drivers`:                              "VBoxSF\nVBoxMouse",
+		`HKLM\SOFTWARE\Test\Path`:                                 `C:\Program Files\Test`,
+		`HKLM\SOFTWARE\Test\Count`:                                "3",
+	}
+	for registryPath, expected := range values {
+		if got, ok := snap.RegistryValues[registryPath]; !ok || got != expected {
+			t.Errorf("%s is %q, expected %q", registryPath, got, expected)
+		}
+	}
+
+	// Only numbers have a type, Count is a string that looks like one.
+	types := map[string]string{
+		`HKLM\SOFTWARE\Test\Level`: "REG_DWORD",
+		`HKLM\SOFTWARE\Test\Limit`: "REG_QWORD",
+	}
+	if len(snap.RegistryTypes) != len(types) {
+		t.Errorf("types are %v, expected %v", snap.RegistryTypes, types)
+	}
+	for registryPath, expected := range types {
+		if got := snap.RegistryTypes[registryPath]; got != expected {
+			t.Errorf("%s is a %q, expected %q", registryPath, got, expected)
+		}
+	}
+
+	if len(snap.RegistryKeys) != 4 {
+		t.Errorf("read %d keys, expected 4: %v", len(snap.RegistryKeys), snap.RegistryKeys)
+	}
+}
+
+func TestParseRegFileErrors(t *testing.T) {
+	tests := map[string]string{
+		"unterminated string": "[HKEY_LOCAL_MACHINE\\SOFTWARE\\Test]\n\"Name\"=\"value\n",
+		"invalid dword":       "[HKEY_LOCAL_MACHINE\\SOFTWARE\\Test]\n\"Name\"=dword:xyz\n",
+		"invalid hex":         "[HKEY_LOCAL_MACHINE\\SOFTWARE\\Test]\n\"Name\"=hex:zz\n",
+		"unexpected line":     "[HKEY_LOCAL_MACHINE\\SOFTWARE\\Test]\nName=value\n",
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseRegFile(name, strings.NewReader(data)); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+func TestReplayRegFile(t *testing.T) {
+	snap := parseTestRegFile(t)
+
+	tests := []struct {
+		name   string
+		vendor signature.Vendor
+		match  bool
+	}{
+		{"GUID", signature.Vendor{RegistryValues: map[string][]string{`HKLM\SYSTEM\CurrentControlSet\Control\Video\{GUID}\0000\Device Description`: {"VMware SVGA*"}}}, true},
+		{"star", signature.Vendor{RegistryKeys: []string{`HKLM\SOFTWARE\Oracle\*`}}, true},
+		{"question mark", signature.Vendor{RegistryValues: map[string][]string{`HKLM\HARDWARE\DEVICEMAP\Scsi\Scsi Port ?\Scsi Bus 0\Target Id 0\Logical Unit Id 0\Identifier`: {"VBOX"}}}, true},
+		{"dword comparison", signature.Vendor{RegistryValues: map[string][]string{`HKLM\SOFTWARE\Test\Level`: {"=3"}}}, true},
+		{"qword comparison", signature.Vendor{RegistryValues: map[string][]string{`HKLM\SOFTWARE\Test\Limit`: {">0xf"}}}, true},
+		{"string isn't a number", signature.Vendor{RegistryValues: map[string][]string{`HKLM\SOFTWARE\Test\Count`: {"=3"}}}, false},
+		{"string that looks like a number", signature.Vendor{RegistryValues: map[string][]string{`HKLM\SOFTWARE\Test\Count`: {"3"}}}, true},
+		{"multi-string", signature.Vendor{RegistryValues: map[string][]string{`HKLM\SOFTWARE\Test\Drivers`: {"VBoxSF"}}}, true},
+		{"no match", signature.Vendor{RegistryValues: map[string][]string{`HKLM\SOFTWARE\Test\Level`: {">3"}}}, false},
+		{"missing key", signature.Vendor{RegistryKeys: []string{`HKLM\SOFTWARE\VMware, Inc.\VMware Tools`}}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := Replay(snap, testSignatures(t, map[string]signature.Vendor{"Test": test.vendor}))
+
+			if matched := registryVendors(r)["Test"]; matched != test.match {
+				t.Errorf("matched is %v, expected %v: %+v", matched, test.match, r.Evidence)
+			}
+		})
+	}
+}
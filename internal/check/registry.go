@@ -0,0 +1,281 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * registry.go
+ * ---
+ * Last Modified: 20/10/2026 12:45PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"strings"
+	"unicode/utf16"
+)
+
+// Firmware strings read for the consistency check.
+const (
+	smbiosManufacturerKey = `HKLM\HARDWARE\Description\System\BIOS\SystemManufacturer`
+	smbiosProductKey      = `HKLM\HARDWARE\Description\System\BIOS\SystemProductName`
+)
+
+// Registry value types, the same numbers Windows and hive files use.
+const (
+	regSZ             = 1
+	regExpandSZ       = 2
+	regBinary         = 3
+	regDWORD          = 4
+	regDWORDBigEndian = 5
+	regLink           = 6
+	regMultiSZ        = 7
+	regQWORD          = 11
+)
+
+// registryReader is read-only access to a registry, either the live one on
+// Windows or a fake built from a snapshot, so the matching can run anywhere.
+type registryReader interface {
+	// OpenKey opens a key by its full path including the hive, e.g. HKLM\SOFTWARE\Oracle.
+	OpenKey(registryPath string) (registryKey, error)
+}
+
+// registryKey is an open key of a registryReader.
+type registryKey interface {
+	SubKeyNames() ([]string, error)
+	ValueNames() ([]string, error)
+	Value(name string) (registryValue, error)
+	Close() error
+}
+
+// decodeRegistryValue decodes the raw data of a value by its type.
+func decodeRegistryValue(valueType uint32, data []byte) registryValue {
+	switch valueType {
+	case regSZ, regExpandSZ, regLink:
+		if values := utf16Strings(data); len(values) > 0 {
+			return stringValue(values[0])
+		}
+		return stringValue("")
+	case regMultiSZ:
+		return stringValue(utf16Strings(data)...)
+	case regDWORD:
+		if len(data) >= 4 {
+			return integerValue(uint64(binary.LittleEndian.Uint32(data)))
+		}
+	case regDWORDBigEndian:
+		if len(data) >= 4 {
+			return integerValue(uint64(binary.BigEndian.Uint32(data)))
+		}
+	case regQWORD:
+		if len(data) >= 8 {
+			return integerValue(binary.LittleEndian.Uint64(data))
+		}
+	}
+
+	return binaryValue(data)
+}
+
+// utf16Strings splits NUL separated UTF-16LE data into its strings, empty ones are dropped.
+func utf16Strings(data []byte) []string {
+	chars := make([]uint16, len(data)/2)
+	for i := range chars {
+		chars[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+
+	var values []string
+	for _, value := range strings.Split(string(utf16.Decode(chars)), "\x00") {
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// registryKeyExists reports whether any key matching registryPath exists,
+// any segment of the path may contain wildcards.
+func registryKeyExists(reg registryReader, registryPath string) bool {
+	for _, key := range expandRegistryKey(reg, splitRegistryPath(registryPath)) {
+		if keyHandle, err := reg.OpenKey(key); err == nil {
+			keyHandle.Close()
+			return true
+		}
+	}
+
+	return false
+}
+
+// registryValueMatches reports whether the value at registryPath matches
+// expected. Both the path and the value may contain wildcards, see registryValue.matches.
+func registryValueMatches(reg registryReader, registryPath string, expected string) bool {
+	segments := splitRegistryPath(registryPath)
+	if len(segments) < 2 {
+		return false
+	}
+	valueName := segments[len(segments)-1]
+
+	for _, key := range expandRegistryKey(reg, segments[:len(segments)-1]) {
+		if registryKeyValueMatches(reg, key, valueName, expected) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func registryKeyValueMatches(reg registryReader, keyPath string, valueName string, expected string) bool {
+	keyHandle, err := reg.OpenKey(keyPath)
+	if err != nil {
+		return false
+	}
+	defer keyHandle.Close()
+
+	names := []string{valueName}
+	if signature.IsPattern(valueName) {
+		if names, err = keyHandle.ValueNames(); err != nil {
+			return false
+		}
+	}
+
+	for _, name := range names {
+		if !matchSegment(valueName, name) {
+			continue
+		}
+
+		if value, err := keyHandle.Value(name); err == nil && value.matches(expected) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// registryString reads the string value at registryPath.
+func registryString(reg registryReader, registryPath string) (string, bool) {
+	segments := splitRegistryPath(registryPath)
+	if len(segments) < 2 {
+		return "", false
+	}
+
+	keyHandle, err := reg.OpenKey(strings.Join(segments[:len(segments)-1], `\`))
+	if err != nil {
+		return "", false
+	}
+	defer keyHandle.Close()
+
+	value, err := keyHandle.Value(segments[len(segments)-1])
+	if err != nil || value.numeric || len(value.strings) == 0 {
+		return "", false
+	}
+
+	return value.strings[0], true
+}
+
+// controlSetKey opens a key below the control set the system boots from and
+// lists its subkeys. Hives map CurrentControlSet, snapshots store ControlSet001.
+func controlSetKey(reg registryReader, subKey string) (string, []string, bool) {
+	for _, controlSet := range []string{`HKLM\SYSTEM\CurrentControlSet`, `HKLM\SYSTEM\ControlSet001`} {
+		keyPath := joinRegistryPath(controlSet, subKey)
+		keyHandle, err := reg.OpenKey(keyPath)
+		if err != nil {
+			continue
+		}
+
+		names, err := keyHandle.SubKeyNames()
+		keyHandle.Close()
+		if err != nil {
+			continue
+		}
+
+		return keyPath, names, true
+	}
+
+	return "", nil, false
+}
+
+// expandRegistryKey resolves a path into every key it could match, starting
+// from its hive. Subkeys are only enumerated for segments with wildcards, so
+// the keys returned aren't guaranteed to exist.
+func expandRegistryKey(reg registryReader, segments []string) []string {
+	return expandRegistrySubKey(reg, segments[0], segments[1:])
+}
+
+func expandRegistrySubKey(reg registryReader, keyPath string, segments []string) []string {
+	if len(segments) == 0 {
+		return []string{keyPath}
+	}
+
+	segment, rest := segments[0], segments[1:]
+	if !signature.IsPattern(segment) {
+		return expandRegistrySubKey(reg, joinRegistryPath(keyPath, segment), rest)
+	}
+
+	keyHandle, err := reg.OpenKey(keyPath)
+	if err != nil {
+		return nil
+	}
+	subKeys, err := keyHandle.SubKeyNames()
+	keyHandle.Close()
+	if err != nil {
+		return nil
+	}
+
+	var keys []string
+	for _, name := range subKeys {
+		if matchSegment(segment, name) {
+			keys = append(keys, expandRegistrySubKey(reg, joinRegistryPath(keyPath, name), rest)...)
+		}
+	}
+
+	return keys
+}
+
+// matchRegistry matches the registry signatures against reg.
+func (s *signatureSet) matchRegistry(reg registryReader, r *Report) {
+	start := len(r.Evidence)
+
+	// https://github.com/CheckPointSW/Evasions/blob/master/_src/Evasions/techniques/registry.md
+	for _, vendor := range vendorNames(s.registryKeys) {
+		for _, key := range s.registryKeys[vendor] {
+			if registryKeyExists(reg, key) {
+				r.Add(registryEvidence(vendor, fmt.Sprintf("%s found in Registry", key), key, key))
+			}
+		}
+	}
+
+	for _, vendor := range vendorNames(s.registryValues) {
+		registryValues := s.registryValues[vendor]
+		for _, registryPath := range vendorNames(registryValues) {
+			for _, value := range registryValues[registryPath] {
+				if registryValueMatches(reg, registryPath, value) {
+					r.Add(registryEvidence(vendor, fmt.Sprintf("Registry Path %s contains %s", registryPath, value), registryPath, value))
+				}
+			}
+		}
+	}
+	attachGuestMetadata(reg, r, start)
+
+	// A recognisable OEM in the firmware strings is a physical claim the consistency check can compare against.
+	manufacturer, ok := registryString(reg, smbiosManufacturerKey)
+	product, _ := registryString(reg, smbiosProductKey)
+	if ok && !s.isSMBIOSPlaceholder(manufacturer) && s.smbiosVendor(manufacturer) == "" && s.smbiosVendor(product) == "" {
+		r.Observe(Observation{Source: "SMBIOS", Value: manufacturer, Vendor: manufacturer})
+	}
+}
+
+// registryEvidence builds VM evidence for a registry hit, keys describing
+// firmware are attached as SMBIOS observations.
+func registryEvidence(vendor string, reason string, registryPath string, value string) Evidence {
+	e := Evidence{Class: ClassVM, Vendor: vendor, Reason: reason}
+
+	upper := strings.ToUpper(registryPath)
+	if strings.Contains(upper, `\HARDWARE\DESCRIPTION\SYSTEM\`) || strings.Contains(upper, `\SYSTEMINFORMATION\`) {
+		e.Observations = append(e.Observations, Observation{Source: "SMBIOS", Value: value, Vendor: vendor, Virtual: true})
+	}
+
+	return e
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * registry_test.go
+ * ---
+ * Last Modified: 20/10/2026 03:20PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"testing"
+)
+
+// testSignatures builds a signature file holding just the given vendors.
+func testSignatures(t *testing.T, vendors map[string]signature.Vendor) *signature.File {
+	t.Helper()
+
+	file := &signature.File{Schema: signature.Schema, Version: 1, Vendors: vendors, Passthrough: signature.Passthrough{IVSHMEM: "1af4:1110"}}
+	if err := file.Validate(); err != nil {
+		t.Fatalf("invalid test signatures: %v", err)
+	}
+
+	return file
+}
+
+// registryVendors returns the vendors of the registry evidence in a report.
+func registryVendors(r *Report) map[string]bool {
+	vendors := make(map[string]bool)
+	for _, e := range r.Evidence {
+		vendors[e.Vendor] = true
+	}
+
+	return vendors
+}
+
+func TestMatchRegistry(t *testing.T) {
+	const video = `HKLM\SYSTEM\CurrentControlSet\Control\Video\{2D3B4F6A-1B2C-4D5E-8F90-A1B2C3D4E5F6}\0000`
+
+	tests := []struct {
+		name   string
+		snap   Snapshot
+		vendor signature.Vendor
+		match  bool
+	}{
+		{
+			name:   "key",
+			snap:   Snapshot{RegistryKeys: []string{`HKLM\SOFTWARE\Oracle\VirtualBox Guest Additions`}},
+			vendor: signature.Vendor{RegistryKeys: []string{`HKLM\SOFTWARE\Oracle\VirtualBox Guest Additions`}},
+			match:  true,
+		},
+		{
+			name:   "key ignores case",
+			snap:   Snapshot{RegistryKeys: []string{`HKLM\SOFTWARE\ORACLE\virtualbox guest additions`}},
+			vendor: signature.Vendor{RegistryKeys: []string{`HKLM\SOFTWARE\Oracle\VirtualBox Guest Additions`}},
+			match:  true,
+		},
+		{
+			name:   "hive ignores case",
+			snap:   Snapshot{RegistryKeys: []string{`HKLM\SOFTWARE\Oracle\VirtualBox Guest Additions`}},
+			vendor: signature.Vendor{RegistryKeys: []string{`hklm\SOFTWARE\Oracle\VirtualBox Guest Additions`}},
+			match:  true,
+		},
+		{
+			name:   "missing key",
+			snap:   Snapshot{RegistryKeys: []string{`HKLM\SOFTWARE\Oracle`}},
+			vendor: signature.Vendor{RegistryKeys: []string{`HKLM\SOFTWARE\Oracle\VirtualBox Guest Additions`}},
+		},
+		{
+			name:   "star in key",
+			snap:   Snapshot{RegistryKeys: []string{`HKLM\SOFTWARE\Oracle\VirtualBox Guest Additions`}},
+			vendor: signature.Vendor{RegistryKeys: []string{`HKLM\SOFTWARE\Oracle\*Guest Additions`}},
+			match:  true,
+		},
+		{
+			name:   "question mark in key",
+			snap:   Snapshot{RegistryKeys: []string{`HKLM\HARDWARE\DEVICEMAP\Scsi\Scsi Port 2`}},
+			vendor: signature.Vendor{RegistryKeys: []string{`HKLM\HARDWARE\DEVICEMAP\Scsi\Scsi Port ?`}},
+			match:  true,
+		},
+		{
+			name:   "question mark is a single character",
+			snap:   Snapshot{RegistryKeys: []string{`HKLM\HARDWARE\DEVICEMAP\Scsi\Scsi Port 12`}},
+			vendor: signature.Vendor{RegistryKeys: []string{`HKLM\HARDWARE\DEVICEMAP\Scsi\Scsi Port ?`}},
+		},
+		{
+			name:   "GUID segment",
+			snap:   Snapshot{RegistryValues: map[string]string{video + `\Device Description`: "VMware SVGA 3D"}},
+			vendor: signature.Vendor{RegistryValues: map[string][]string{`HKLM\SYSTEM\CurrentControlSet\Control\Video\{GUID}\0000\Device Description`: {"VMware SVGA*"}}},
+			match:  true,
+		},
+		{
+			name:   "GUID segment needs a GUID",
+			snap:   Snapshot{RegistryValues: map[string]string{`HKLM\SYSTEM\CurrentControlSet\Control\Video\Other\0000\Device Description`: "VMware SVGA 3D"}},
+			vendor: signature.Vendor{RegistryValues: map[string][]string{`HKLM\SYSTEM\CurrentControlSet\Control\Video\{GUID}\0000\Device Description`: {"VMware SVGA*"}}},
+		},
+		{
+			name:   "plain value is contained",
+			snap:   Snapshot{RegistryValues: map[string]string{`HKLM\HARDWARE\Description\System\SystemBiosVersion`: "VBOX   - 1"}},
+			vendor: signature.Vendor{RegistryValues: map[string][]string{`HKLM\HARDWARE\Description\System\SystemBiosVersion`: {"vbox"}}},
+			match:  true,
+		},
+		{
+			name:   "pattern value matches all of it",
+			snap:   Snapshot{RegistryValues: map[string]string{`HKLM\HARDWARE\Description\System\BIOS\SystemProductName`: "Virtual Machine"}},
+			vendor: signature.Vendor{RegistryValues: map[string][]string{`HKLM\HARDWARE\Description\System\BIOS\SystemProductName`: {"VIRTUALBOX*"}}},
+		},
+		{
+			name:   "multi-string element",
+			snap:   Snapshot{RegistryValues: map[string]string{`HKLM\SOFTWARE\Test\Drivers`: "VBoxSF\nVBoxMouse"}},
+			vendor: signature.Vendor{RegistryValues: map[string][]string{`HKLM\SOFTWARE\Test\Drivers`: {"VBoxM?use"}}},
+			match:  true,
+		},
+		{
+			name:   "numeric comparison",
+			snap:   Snapshot{RegistryValues: map[string]string{`HKLM\SOFTWARE\Test\Level`: "3"}, RegistryTypes: map[string]string{`HKLM\SOFTWARE\Test\Level`: "REG_DWORD"}},
+			vendor: signature.Vendor{RegistryValues: map[string][]string{`HKLM\SOFTWARE\Test\Level`: {">=0x2"}}},
+			match:  true,
+		},
+		{
+			name:   "failed numeric comparison",
+			snap:   Snapshot{RegistryValues: map[string]string{`HKLM\SOFTWARE\Test\Level`: "3"}, RegistryTypes: map[string]string{`HKLM\SOFTWARE\Test\Level`: "REG_QWORD"}},
+			vendor: signature.Vendor{RegistryValues: map[string][]string{`HKLM\SOFTWARE\Test\Level`: {"<3"}}},
+		},
+		{
+			name:   "string that looks like a number isn't one",
+			snap:   Snapshot{RegistryValues: map[string]string{`HKLM\SOFTWARE\Test\Level`: "3"}},
+			vendor: signature.Vendor{RegistryValues: map[string][]string{`HKLM\SOFTWARE\Test\Level`: {">=0x2"}}},
+		},
+		{
+			name:   "comparison needs a number",
+			snap:   Snapshot{RegistryValues: map[string]string{`HKLM\SOFTWARE\Test\Level`: "high"}},
+			vendor: signature.Vendor{RegistryValues: map[string][]string{`HKLM\SOFTWARE\Test\Level`: {"!=0"}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := compile(testSignatures(t, map[string]signature.Vendor{"Test": test.vendor}))
+			r := &Report{}
+			s.matchRegistry(snapshotRegistry(&test.snap), r)
+
+			if matched := registryVendors(r)["Test"]; matched != test.match {
+				t.Errorf("matched is %v, expected %v: %+v", matched, test.match, r.Evidence)
+			}
+		})
+	}
+}
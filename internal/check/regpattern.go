@@ -0,0 +1,152 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * regpattern.go
+ * ---
+ * Last Modified: 19/10/2026 07:48PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf16"
+)
+
+// Compiled patterns, signatures are matched against every subkey so they're only compiled once.
+var patterns sync.Map
+
+func compiledPattern(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+
+	re, err := signature.CompilePattern(pattern)
+	if err != nil {
+		// Signatures are validated before they're used, this can't match anything.
+		re = regexp.MustCompile(`$^`)
+	}
+	patterns.Store(pattern, re)
+
+	return re
+}
+
+// splitRegistryPath splits a registry path into its segments.
+func splitRegistryPath(registryPath string) []string {
+	return strings.Split(strings.Trim(registryPath, `\`), `\`)
+}
+
+// joinRegistryPath appends a key name to a registry path.
+func joinRegistryPath(registryPath string, name string) string {
+	if registryPath == "" {
+		return name
+	}
+
+	return registryPath + `\` + name
+}
+
+// matchSegment matches a single key or value name against a segment of a
+// signature, case-insensitively. Segments may contain wildcards.
+func matchSegment(pattern string, name string) bool {
+	if !signature.IsPattern(pattern) {
+		return strings.EqualFold(pattern, name)
+	}
+
+	return compiledPattern(pattern).MatchString(name)
+}
+
+// matchRegistryValue matches value data against a signature value.
+//
+// Values with wildcards have to match the whole data, e.g. "VMware SVGA*",
+// plain values only have to be contained in it. Both ignore case.
+func matchRegistryValue(pattern string, data string) bool {
+	if signature.IsPattern(pattern) {
+		return compiledPattern(pattern).MatchString(data)
+	}
+
+	return strings.Contains(strings.ToLower(data), strings.ToLower(pattern))
+}
+
+// registryValue is a registry value of any type, decoded into what signatures match against.
+type registryValue struct {
+	strings []string // Every element of a REG_MULTI_SZ and every string found in binary data.
+	integer uint64
+	numeric bool // REG_DWORD and REG_QWORD.
+}
+
+func stringValue(values ...string) registryValue {
+	return registryValue{strings: values}
+}
+
+// integerValue keeps the decimal and hex forms too, so text signatures still work against numbers.
+func integerValue(n uint64) registryValue {
+	return registryValue{
+		strings: []string{strconv.FormatUint(n, 10), "0x" + strconv.FormatUint(n, 16)},
+		integer: n,
+		numeric: true,
+	}
+}
+
+// binaryValue pulls the printable runs out of binary data, firmware blobs
+// like SMBIOS tables store their strings as ASCII or UTF-16.
+func binaryValue(data []byte) registryValue {
+	return stringValue(append(printableRuns(data, 1), printableRuns(data, 2)...)...)
+}
+
+// Shorter runs in binary data are mostly noise.
+const minPrintableRun = 4
+
+// printableRuns returns the runs of printable ASCII in data, reading
+// characters width bytes at a time. A width of 2 reads UTF-16LE.
+func printableRuns(data []byte, width int) []string {
+	var runs []string
+	var run []uint16
+
+	flush := func() {
+		if len(run) >= minPrintableRun {
+			runs = append(runs, string(utf16.Decode(run)))
+		}
+		run = run[:0]
+	}
+
+	for i := 0; i+width <= len(data); i += width {
+		c := uint16(data[i])
+		if width == 2 {
+			c |= uint16(data[i+1]) << 8
+		}
+
+		if c >= 0x20 && c < 0x7f {
+			run = append(run, c)
+		} else {
+			flush()
+		}
+	}
+	flush()
+
+	return runs
+}
+
+// matches reports whether the value matches a signature value.
+//
+// Comparisons like ">=0x2" only match DWORD and QWORD values, anything else
+// is matched against every string in the value, see matchRegistryValue.
+func (v registryValue) matches(expected string) bool {
+	if comparison, err := signature.ParseComparison(expected); err != nil || comparison != nil {
+		return comparison != nil && v.numeric && comparison.Matches(v.integer)
+	}
+
+	for _, data := range v.strings {
+		if matchRegistryValue(expected, data) {
+			return true
+		}
+	}
+
+	return false
+}
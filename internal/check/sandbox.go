@@ -0,0 +1,96 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * sandbox.go
+ * ---
+ * Last Modified: 20/10/2026 03:30PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VendorWindowsSandbox is the vendor of evidence for Windows Sandbox,
+// Application Guard and Hyper-V isolated containers. They're all the same
+// container platform underneath and share markers, so they aren't told apart.
+const VendorWindowsSandbox = "Windows Sandbox"
+
+const (
+	// The account Sandbox and Application Guard run the desktop as. It exists,
+	// disabled, on every host that has either feature enabled.
+	sandboxUser = "WDAGUtilityAccount"
+	// Set by the container platform in the guest, only Hyper-V isolation is
+	// counted, the other values aren't documented.
+	containerTypeValue  = "ContainerType"
+	containerTypeHyperV = 2
+	// Container Manager runs inside the container, the Host Compute Service
+	// on the host that starts it.
+	containerManagerService = "CmService"
+	hostComputeService      = "vmcompute"
+)
+
+// sandboxServices are the services matchSandbox looks at.
+var sandboxServices = []string{containerManagerService, hostComputeService}
+
+// matchSandbox checks for a Windows container, user is the account the
+// process runs as and services the state of sandboxServices, either can be
+// empty when it's not known, e.g. for hives.
+//
+// The CPUID partition flags (leaf 0x40000003) aren't checked, there's no
+// documented flag for a container partition, so a container none of these
+// markers catch is reported as plain Hyper-V.
+func matchSandbox(reg registryReader, user string, services []service, r *Report) {
+	if user != "" {
+		// Drop the domain, which is the machine name.
+		if i := strings.LastIndex(user, `\`); i != -1 {
+			user = user[i+1:]
+		}
+
+		if strings.EqualFold(user, sandboxUser) {
+			r.Add(Evidence{
+				Class:  ClassVM,
+				Vendor: VendorWindowsSandbox,
+				Reason: fmt.Sprintf("Running as %s", sandboxUser),
+			})
+		}
+	}
+
+	if controlKey, _, ok := controlSetKey(reg, "Control"); ok {
+		if keyHandle, err := reg.OpenKey(controlKey); err == nil {
+			if value, err := keyHandle.Value(containerTypeValue); err == nil && value.numeric && value.integer == containerTypeHyperV {
+				r.Add(registryEvidence(VendorWindowsSandbox, fmt.Sprintf("%s is %d", containerTypeValue, value.integer), joinRegistryPath(controlKey, containerTypeValue), fmt.Sprint(value.integer)))
+			}
+			keyHandle.Close()
+		}
+	}
+
+	states := make(map[string]string)
+	for _, svc := range services {
+		states[strings.ToLower(svc.name)] = svc.state
+	}
+
+	switch {
+	case states[strings.ToLower(hostComputeService)] == "running":
+		// A host that can run containers, which says nothing about this system.
+		r.Add(Evidence{
+			Class:  ClassHypervisorInstalled,
+			Vendor: VendorWindowsSandbox,
+			Reason: fmt.Sprintf("Service %s is running", hostComputeService),
+		})
+	case states[strings.ToLower(containerManagerService)] == "running":
+		// Container Manager without the Host Compute Service is what a container
+		// looks like from inside, but it's started on demand on hosts too.
+		r.Add(Evidence{
+			Class:  ClassVM,
+			Vendor: VendorWindowsSandbox,
+			Reason: fmt.Sprintf("Service %s is running without %s", containerManagerService, hostComputeService),
+			Weak:   true,
+		})
+	}
+}
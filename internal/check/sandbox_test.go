@@ -0,0 +1,42 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * sandbox_test.go
+ * ---
+ * Last Modified: 20/10/2026 03:15PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import "testing"
+
+func TestMatchSandboxContainerType(t *testing.T) {
+	const containerType = `HKLM\SYSTEM\CurrentControlSet\Control\ContainerType`
+
+	tests := []struct {
+		name      string
+		value     string
+		valueType string
+		match     bool
+	}{
+		{name: "Hyper-V isolation", value: "2", valueType: "REG_DWORD", match: true},
+		{name: "other type", value: "1", valueType: "REG_DWORD"},
+		{name: "not a number", value: "2 ", valueType: "REG_DWORD"},
+		{name: "string", value: "2", valueType: "REG_SZ"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &Report{}
+			snap := &Snapshot{RegistryValues: map[string]string{containerType: test.value}, RegistryTypes: map[string]string{containerType: test.valueType}}
+			matchSandbox(snapshotRegistry(snap), "", nil, r)
+
+			if matched := registryVendors(r)[VendorWindowsSandbox]; matched != test.match {
+				t.Errorf("matched is %v, expected %v: %+v", matched, test.match, r.Evidence)
+			}
+		})
+	}
+}
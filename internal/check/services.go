@@ -0,0 +1,128 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * services.go
+ * ---
+ * Last Modified: 20/10/2026 12:35PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Service types and start types stored under the Services key.
+const (
+	serviceKernelDriver     = 0x01
+	serviceFileSystemDriver = 0x02
+	serviceStartDisabled    = 4
+)
+
+// service is a Windows service or driver as the Service Control Manager reports it.
+type service struct {
+	name      string
+	state     string // e.g. "running" or "stopped".
+	imagePath string
+	signer    string // Subject of the embedded Authenticode signature, empty for catalog signed or unsigned images.
+	driver    bool
+}
+
+// serviceVendor returns the vendor whose guest tools install a service, if any.
+func (s *signatureSet) serviceVendor(name string) string {
+	for _, vendor := range vendorNames(s.services) {
+		for _, pattern := range s.services[vendor] {
+			if matchSegment(pattern, name) {
+				return vendor
+			}
+		}
+	}
+
+	return ""
+}
+
+// matchServices checks services and drivers against the guest tools of every
+// vendor. A running one is evidence of a VM, one that's only installed could
+// be left over from a migrated or converted machine so it's only weak evidence.
+func (s *signatureSet) matchServices(services []service, r *Report) {
+	for _, svc := range services {
+		vendor := s.serviceVendor(svc.name)
+		if vendor == "" {
+			continue
+		}
+
+		kind := "Service"
+		if svc.driver {
+			kind = "Driver"
+		}
+
+		details := svc.imagePath
+		if svc.signer != "" {
+			details = fmt.Sprintf("%s, signed by %s", svc.imagePath, svc.signer)
+		}
+
+		if svc.state != "running" {
+			// Registries read offline can't tell if it's running.
+			state := "installed"
+			if svc.state != "" {
+				state = "installed but " + svc.state
+			}
+
+			r.Add(Evidence{
+				Class:  ClassVM,
+				Vendor: vendor,
+				Reason: fmt.Sprintf("%s %s is %s (%s)", kind, svc.name, state, details),
+				Weak:   true,
+			})
+			continue
+		}
+
+		r.Add(Evidence{
+			Class:  ClassVM,
+			Vendor: vendor,
+			Reason: fmt.Sprintf("%s %s is running (%s)", kind, svc.name, details),
+			Observations: []Observation{
+				{Source: kind, Value: svc.name, Vendor: vendor, Virtual: true},
+			},
+		})
+	}
+}
+
+// registryServices reads the services a signature matches from the Services
+// key, for registries that aren't live, i.e. hives and snapshots. Only the
+// state of disabled ones is known.
+func (s *signatureSet) registryServices(reg registryReader) []service {
+	servicesKey, names, ok := controlSetKey(reg, "Services")
+	if !ok {
+		return nil
+	}
+
+	var services []service
+	for _, name := range names {
+		if s.serviceVendor(name) == "" {
+			continue
+		}
+
+		svc := service{name: name, imagePath: "no image"}
+		if keyHandle, err := reg.OpenKey(joinRegistryPath(servicesKey, name)); err == nil {
+			if value, err := keyHandle.Value("Type"); err == nil && value.numeric {
+				svc.driver = value.integer&(serviceKernelDriver|serviceFileSystemDriver) != 0
+			}
+			if value, err := keyHandle.Value("Start"); err == nil && value.numeric && value.integer == serviceStartDisabled {
+				svc.state = "disabled"
+			}
+			if value, err := keyHandle.Value("ImagePath"); err == nil && !value.numeric && len(value.strings) > 0 {
+				svc.imagePath = strings.TrimPrefix(value.strings[0], `\??\`)
+			}
+			keyHandle.Close()
+		}
+
+		services = append(services, svc)
+	}
+
+	return services
+}
@@ -0,0 +1,207 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * signatures.go
+ * ---
+ * Last Modified: 20/10/2026 03:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// signatureSet is a signature file compiled into the lookup tables the checks use.
+type signatureSet struct {
+	version int
+
+	registryKeys   map[string][]string
+	registryValues map[string]map[string][]string
+	files          map[string][]string
+	services       map[string][]string
+	hardwareIDs    map[string][]string
+	deviceObjects  map[string][]string
+	acpiOEMIDs     map[string][]string
+
+	ouis                    []ouiEntry
+	hostAdapterNames        map[string][]string
+	hostAdapterDescriptions map[string][]string
+	nicDrivers              map[string][]string
+	nicBuses                map[string]string // bus -> vendor
+	natDefaults             []natDefault
+
+	smbiosVendors      map[string][]string
+	smbiosPlaceholders []string
+	pciVendors         map[string]string // vendor ID -> vendor
+	ioregistryVendors  map[string][]string
+
+	ivshmemDevice    string
+	virtualDisplays  map[string]string
+	gpuVendors       map[string]string
+	lookingGlassKeys []string
+
+	wineKeys []string
+}
+
+var (
+	embedded *signatureSet
+	active   atomic.Pointer[signatureSet]
+)
+
+func init() {
+	file, err := signature.Parse(signature.Embedded)
+	if err != nil {
+		panic(fmt.Sprintf("embedded signatures: %v", err))
+	}
+
+	embedded = compile(file)
+	active.Store(embedded)
+}
+
+// sigs returns the signatures the checks are currently using.
+func sigs() *signatureSet {
+	return active.Load()
+}
+
+// SignatureVersion returns the version of the signatures the checks are using.
+func SignatureVersion() int {
+	return sigs().version
+}
+
+// ErrNoReleaseKey is returned by LoadSignatures while no release key is pinned.
+var ErrNoReleaseKey = signature.ErrNoReleaseKey
+
+// LoadSignatures replaces the signatures with those in a signed bundle.
+//
+// The bundle has to be signed with the pinned release key and be newer than
+// the embedded signatures, if it isn't the embedded ones are used instead.
+// Without a pinned key every bundle fails with ErrNoReleaseKey.
+func LoadSignatures(bundle []byte) error {
+	file, err := signature.Open(bundle, signature.PublicKey)
+	if err == nil && file.Version <= embedded.version {
+		err = fmt.Errorf("bundle version %d isn't newer than the embedded %d", file.Version, embedded.version)
+	}
+
+	if err != nil {
+		active.Store(embedded)
+		return err
+	}
+
+	active.Store(compile(file))
+	return nil
+}
+
+// ResetSignatures goes back to the signatures embedded in the binary.
+func ResetSignatures() {
+	active.Store(embedded)
+}
+
+// compile builds the lookup tables from a validated signature file.
+func compile(file *signature.File) *signatureSet {
+	s := &signatureSet{
+		version:                 file.Version,
+		registryKeys:            make(map[string][]string),
+		registryValues:          make(map[string]map[string][]string),
+		files:                   make(map[string][]string),
+		services:                make(map[string][]string),
+		hardwareIDs:             make(map[string][]string),
+		deviceObjects:           make(map[string][]string),
+		acpiOEMIDs:              make(map[string][]string),
+		hostAdapterNames:        make(map[string][]string),
+		hostAdapterDescriptions: make(map[string][]string),
+		nicDrivers:              make(map[string][]string),
+		nicBuses:                make(map[string]string),
+		smbiosVendors:           make(map[string][]string),
+		smbiosPlaceholders:      file.SMBIOSPlaceholders,
+		pciVendors:              make(map[string]string),
+		ioregistryVendors:       make(map[string][]string),
+		ivshmemDevice:           file.Passthrough.IVSHMEM,
+		virtualDisplays:         file.Passthrough.Displays,
+		gpuVendors:              file.Passthrough.GPUVendors,
+		lookingGlassKeys:        file.Passthrough.RegistryKeys,
+		wineKeys:                file.CompatibilityLayers.RegistryKeys,
+	}
+
+	// Vendors are walked in order so ties in the tables come out the same every run.
+	for _, name := range vendorNames(file.Vendors) {
+		vendor := file.Vendors[name]
+		set(s.registryKeys, name, vendor.RegistryKeys)
+		set(s.files, name, vendor.Files)
+		set(s.services, name, vendor.Services)
+		set(s.hardwareIDs, name, vendor.HardwareIDs)
+		set(s.deviceObjects, name, vendor.DeviceObjects)
+		set(s.acpiOEMIDs, name, vendor.ACPIOEMIDs)
+		set(s.hostAdapterNames, name, lower(vendor.HostAdapterNames))
+		set(s.hostAdapterDescriptions, name, vendor.HostAdapterDescriptions)
+		set(s.nicDrivers, name, vendor.NICDrivers)
+		set(s.smbiosVendors, name, vendor.SMBIOS)
+		set(s.ioregistryVendors, name, vendor.IORegistryVendors)
+
+		if len(vendor.RegistryValues) > 0 {
+			s.registryValues[name] = vendor.RegistryValues
+		}
+		for _, bus := range vendor.NICBuses {
+			s.nicBuses[strings.ToLower(bus)] = name
+		}
+		for _, id := range vendor.PCIVendors {
+			s.pciVendors[id] = name
+		}
+		for _, oui := range vendor.OUI {
+			prefix, _ := signature.ParseOUI(oui) // Already validated.
+			s.ouis = append(s.ouis, ouiEntry{prefix: prefix, vendor: name})
+		}
+	}
+
+	// Longest prefix first so Google's two byte prefix never shadows a full OUI.
+	sort.SliceStable(s.ouis, func(i, j int) bool {
+		return len(s.ouis[i].prefix) > len(s.ouis[j].prefix)
+	})
+
+	for _, nat := range file.NATDefaults {
+		s.natDefaults = append(s.natDefaults, natDefault{
+			vendor:        nat.Vendor,
+			name:          nat.Name,
+			network:       nat.Network,
+			gateway:       nat.Gateway,
+			gatewayHost:   nat.GatewayHost,
+			gatewayVendor: nat.GatewayVendor,
+		})
+	}
+
+	return s
+}
+
+// vendorNames returns the vendors in table sorted by name, so checks that
+// walk a table report their findings in the same order on every run.
+func vendorNames[V any](table map[string]V) []string {
+	names := make([]string, 0, len(table))
+	for name := range table {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func set(table map[string][]string, vendor string, values []string) {
+	if len(values) > 0 {
+		table[vendor] = values
+	}
+}
+
+func lower(values []string) []string {
+	out := make([]string, len(values))
+	for i, value := range values {
+		out[i] = strings.ToLower(value)
+	}
+
+	return out
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * signatures_test.go
+ * ---
+ * Last Modified: 20/10/2026 02:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"testing"
+)
+
+// withKey pins a throwaway release key for the length of a test.
+func withKey(t *testing.T) ed25519.PrivateKey {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pinned := signature.PublicKey
+	signature.PublicKey = public
+	t.Cleanup(func() {
+		signature.PublicKey = pinned
+		ResetSignatures()
+	})
+
+	return private
+}
+
+// embeddedWith returns the embedded signature file with its schema and version changed.
+func embeddedWith(t *testing.T, schema int, version int) []byte {
+	var file map[string]any
+	if err := json.Unmarshal(signature.Embedded, &file); err != nil {
+		t.Fatal(err)
+	}
+	file["schema"] = schema
+	file["version"] = version
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+// sign signs payload without validating it, unlike signature.Seal.
+func sign(t *testing.T, payload []byte, key ed25519.PrivateKey) []byte {
+	bundle, err := json.Marshal(signature.Bundle{Payload: payload, Signature: ed25519.Sign(key, payload)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bundle
+}
+
+func TestLoadSignatures(t *testing.T) {
+	key := withKey(t)
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	newer := embedded.version + 1
+
+	tests := []struct {
+		name   string
+		bundle []byte
+	}{
+		{"bad signature", sign(t, embeddedWith(t, signature.Schema, newer), otherKey)},
+		{"older version", sign(t, embeddedWith(t, signature.Schema, embedded.version-1), key)},
+		{"same version", sign(t, embeddedWith(t, signature.Schema, embedded.version), key)},
+		{"schema mismatch", sign(t, embeddedWith(t, signature.Schema+1, newer), key)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Start from a loaded bundle so falling back is visible.
+			if err := LoadSignatures(sign(t, embeddedWith(t, signature.Schema, newer), key)); err != nil {
+				t.Fatalf("valid bundle rejected: %v", err)
+			}
+			if sigs() == embedded {
+				t.Fatal("valid bundle wasn't loaded")
+			}
+
+			if err := LoadSignatures(test.bundle); err == nil {
+				t.Fatal("bundle wasn't rejected")
+			}
+			if sigs() != embedded || SignatureVersion() != embedded.version {
+				t.Errorf("using version %d, expected the embedded %d", SignatureVersion(), embedded.version)
+			}
+		})
+	}
+}
+
+func TestLoadSignaturesWithoutKey(t *testing.T) {
+	key := withKey(t)
+	signature.PublicKey = nil
+
+	if err := LoadSignatures(sign(t, embeddedWith(t, signature.Schema, embedded.version+1), key)); !errors.Is(err, ErrNoReleaseKey) {
+		t.Fatalf("error is %v, expected %v", err, ErrNoReleaseKey)
+	}
+	if sigs() != embedded {
+		t.Error("not using the embedded signatures")
+	}
+}
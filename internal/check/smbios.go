@@ -0,0 +1,49 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * smbios.go
+ * ---
+ * Last Modified: 20/10/2026 01:30PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"strings"
+)
+
+// smbiosVendor returns the hypervisor an SMBIOS string belongs to, if any.
+// When several names are in it the one found first wins, then the longest,
+// so "KVM Virtual Machine" is KVM and not Hyper-V's "Virtual Machine".
+func (s *signatureSet) smbiosVendor(value string) string {
+	value = strings.ToLower(value)
+
+	match, at, length := "", -1, 0
+	for _, vendor := range vendorNames(s.smbiosVendors) {
+		for _, name := range s.smbiosVendors[vendor] {
+			i := strings.Index(value, strings.ToLower(name))
+			if i == -1 {
+				continue
+			}
+
+			if at == -1 || i < at || (i == at && len(name) > length) {
+				match, at, length = vendor, i, len(name)
+			}
+		}
+	}
+
+	return match
+}
+
+func (s *signatureSet) isSMBIOSPlaceholder(value string) bool {
+	for _, placeholder := range s.smbiosPlaceholders {
+		if strings.EqualFold(strings.TrimSpace(value), placeholder) {
+			return true
+		}
+	}
+
+	return false
+}
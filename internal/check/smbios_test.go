@@ -0,0 +1,43 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * smbios_test.go
+ * ---
+ * Last Modified: 20/10/2026 01:30PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"testing"
+)
+
+func TestSMBIOSVendor(t *testing.T) {
+	s := compile(testSignatures(t, map[string]signature.Vendor{
+		"Hyper-V":    {SMBIOS: []string{"Virtual Machine"}},
+		"KVM":        {SMBIOS: []string{"KVM"}},
+		"VirtualBox": {SMBIOS: []string{"innotek GmbH", "VirtualBox"}},
+		"Short":      {SMBIOS: []string{"Virtual"}},
+	}))
+
+	tests := []struct {
+		value  string
+		vendor string
+	}{
+		{value: "Virtual Machine", vendor: "Hyper-V"},
+		{value: "KVM Virtual Machine", vendor: "KVM"},
+		{value: "Standard PC (Q35 + ICH9, 2009) KVM", vendor: "KVM"},
+		{value: "innotek gmbh", vendor: "VirtualBox"},
+		{value: "ASUSTeK COMPUTER INC.", vendor: ""},
+	}
+
+	for _, test := range tests {
+		if vendor := s.smbiosVendor(test.value); vendor != test.vendor {
+			t.Errorf("%q is %q, expected %q", test.value, vendor, test.vendor)
+		}
+	}
+}
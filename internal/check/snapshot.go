@@ -0,0 +1,160 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * snapshot.go
+ * ---
+ * Last Modified: 20/10/2026 03:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Snapshot is the raw data the checks read from a system, captured so a
+// signature file can be replayed against it on any machine.
+type Snapshot struct {
+	Name           string                         `json:"name"`
+	RegistryKeys   []string                       `json:"registry_keys,omitempty"`   // Keys that exist, along with every key above them.
+	RegistryValues map[string]string              `json:"registry_values,omitempty"` // Value path to its data, multi-strings are one per line and numbers decimal or 0x hex.
+	RegistryTypes  map[string]string              `json:"registry_types,omitempty"`  // Value path to REG_DWORD or REG_QWORD for numbers, every other value is a string.
+	Files          []string                       `json:"files,omitempty"`           // Files that exist, e.g. C:\Windows\System32\drivers\VBoxGuest.sys.
+	MACs           []string                       `json:"macs,omitempty"`
+	SMBIOS         []string                       `json:"smbios,omitempty"` // Manufacturer, product, board and BIOS vendor strings.
+	PCI            []string                       `json:"pci,omitempty"`    // vendor:device pairs.
+	NICs           []SnapshotNIC                  `json:"nics,omitempty"`
+	IORegistry     []string                       `json:"ioregistry,omitempty"`     // Manufacturer and Vendor Name values.
+	DeviceObjects  []string                       `json:"device_objects,omitempty"` // Device and pipe names that exist, e.g. \\.\pipe\VBoxTrayIPC.
+	WMI            map[string][]map[string]string `json:"wmi,omitempty"`            // WMI class to its instances, each property to its value as a string.
+	User           string                         `json:"user,omitempty"`           // Account the capture ran as, e.g. SANDBOX\WDAGUtilityAccount.
+}
+
+// SnapshotNIC is the inventory of a single network interface, see nic.
+type SnapshotNIC struct {
+	Name      string `json:"name"`
+	Driver    string `json:"driver,omitempty"`
+	Bus       string `json:"bus,omitempty"`
+	ID        string `json:"id,omitempty"`
+	Subsystem string `json:"subsystem,omitempty"`
+}
+
+// Replay runs the signature matching behind every check against a snapshot.
+//
+// Checks that don't depend on signatures, like CPUID, can't be replayed.
+func Replay(snap *Snapshot, file *signature.File) *Report {
+	s := compile(file)
+	r := &Report{}
+
+	reg := snapshotRegistry(snap)
+	s.matchRegistry(reg, r)
+	s.matchServices(s.registryServices(reg), r)
+	s.matchDevices(registryDevices(reg), r)
+	matchSandbox(reg, snap.User, nil, r)
+	s.matchWine(reg, wineState{}, r)
+
+	for _, vendor := range vendorNames(s.files) {
+		for _, file := range s.files[vendor] {
+			for _, existing := range snap.Files {
+				if strings.EqualFold(rootPath(existing), rootPath(file)) {
+					r.Add(Evidence{Class: ClassVM, Vendor: vendor, Reason: fmt.Sprintf("%s file exists", file)})
+				}
+			}
+		}
+	}
+
+	for _, mac := range snap.MACs {
+		addr, err := net.ParseMAC(mac)
+		if err != nil {
+			continue
+		}
+
+		if vendor, ok := s.ouiVendor(addr); ok {
+			r.Add(Evidence{
+				Class:        ClassVM,
+				Vendor:       vendor,
+				Reason:       "OUI Prefix matches " + vendor,
+				Observations: []Observation{{Source: "MAC", Value: mac, Vendor: vendor, Virtual: true}},
+			})
+		}
+	}
+
+	for _, value := range snap.SMBIOS {
+		if vendor := s.smbiosVendor(value); vendor != "" {
+			r.Add(Evidence{
+				Class:        ClassVM,
+				Vendor:       vendor,
+				Reason:       fmt.Sprintf("SMBIOS string is %s", value),
+				Observations: []Observation{{Source: "SMBIOS", Value: value, Vendor: vendor, Virtual: true}},
+			})
+		}
+	}
+
+	for _, id := range snap.PCI {
+		if vendor, ok := s.pciVendors[pciVendorID(strings.ToLower(id))]; ok {
+			r.Add(Evidence{
+				Class:        ClassVM,
+				Vendor:       vendor,
+				Reason:       fmt.Sprintf("PCI device %s belongs to %s", id, vendor),
+				Observations: []Observation{{Source: "PCI", Value: id, Vendor: vendor, Virtual: true}},
+			})
+		}
+	}
+
+	for _, n := range snap.NICs {
+		if vendor, why := s.nicVendor(nic{name: n.Name, driver: n.Driver, bus: n.Bus, id: n.ID, subsystem: n.Subsystem}); vendor != "" {
+			r.Add(Evidence{Class: ClassVM, Vendor: vendor, Reason: fmt.Sprintf("%s %s", n.Name, why)})
+		}
+	}
+
+	s.matchIORegistryVendors(snap.IORegistry, r)
+
+	s.matchDeviceObjects(func(object string) bool {
+		for _, existing := range snap.DeviceObjects {
+			if strings.EqualFold(existing, object) {
+				return true
+			}
+		}
+		return false
+	}, r)
+
+	if len(snap.WMI) > 0 {
+		wmi := make(memoryWMI)
+		for class, instances := range snap.WMI {
+			for _, instance := range instances {
+				wmi[class] = append(wmi[class], instance)
+			}
+		}
+		s.matchWMI(wmi, r)
+	}
+
+	Consistency(r)
+
+	return r
+}
+
+// Types of numeric values in Snapshot.RegistryTypes.
+const (
+	snapshotDWORD = "REG_DWORD"
+	snapshotQWORD = "REG_QWORD"
+)
+
+// snapshotValue turns captured value data back into a value, see
+// Snapshot.RegistryValues. Data is only a number when its type says so, a
+// REG_SZ of "3" stays a string like it is in the live registry.
+func snapshotValue(data string, valueType string) registryValue {
+	if strings.EqualFold(valueType, snapshotDWORD) || strings.EqualFold(valueType, snapshotQWORD) {
+		if n, err := strconv.ParseUint(data, 0, 64); err == nil {
+			return integerValue(n)
+		}
+	}
+
+	return stringValue(strings.Split(data, "\n")...)
+}
@@ -0,0 +1,98 @@
+//go:build windows
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * win_devices.go
+ * ---
+ * Last Modified: 20/10/2026 10:20AM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// Devices checks the hardware IDs of every device that's attached right now,
+// unlike the Enum key SetupAPI doesn't list devices that were attached once.
+func Devices(r *Report) {
+	sigs().matchDevices(presentDevices(), r)
+}
+
+// presentDevices enumerates the present devices of every class through SetupAPI.
+func presentDevices() []pnpDevice {
+	return presentClassDevices(nil)
+}
+
+// presentClassDevices enumerates the present devices of a setup class, every
+// class if it's nil.
+func presentClassDevices(class *windows.GUID) []pnpDevice {
+	flags := windows.DIGCF_PRESENT
+	if class == nil {
+		flags |= windows.DIGCF_ALLCLASSES
+	}
+
+	devInfo, err := windows.SetupDiGetClassDevsEx(class, "", 0, flags, 0, "")
+	if err != nil {
+		return nil
+	}
+	defer devInfo.Close()
+
+	var devices []pnpDevice
+	for i := 0; ; i++ {
+		data, err := devInfo.EnumDeviceInfo(i)
+		if err == windows.ERROR_NO_MORE_ITEMS {
+			break
+		}
+		if err != nil {
+			continue
+		}
+
+		// DIGCF_PRESENT goes by what the PnP manager last saw, CfgMgr32 has
+		// no devnode for a device that's gone since.
+		var status, problem uint32
+		if windows.CM_Get_DevNode_Status(&status, &problem, data.DevInst, 0) != nil {
+			continue
+		}
+
+		device := pnpDevice{present: true}
+		device.instanceID, _ = devInfo.DeviceInstanceID(data)
+		device.hardwareIDs = deviceProperties(devInfo, data, windows.SPDRP_HARDWAREID)
+		device.compatibleIDs = deviceProperties(devInfo, data, windows.SPDRP_COMPATIBLEIDS)
+		device.friendlyName = deviceProperty(devInfo, data, windows.SPDRP_FRIENDLYNAME)
+		if device.friendlyName == "" {
+			device.friendlyName = deviceProperty(devInfo, data, windows.SPDRP_DEVICEDESC)
+		}
+		device.manufacturer = deviceProperty(devInfo, data, windows.SPDRP_MFG)
+
+		devices = append(devices, device)
+	}
+
+	return devices
+}
+
+// deviceProperties reads a REG_MULTI_SZ device property.
+func deviceProperties(devInfo windows.DevInfo, data *windows.DevInfoData, property windows.SPDRP) []string {
+	value, err := devInfo.DeviceRegistryProperty(data, property)
+	if err != nil {
+		return nil
+	}
+
+	values, _ := value.([]string)
+	return values
+}
+
+// deviceProperty reads a REG_SZ device property.
+func deviceProperty(devInfo windows.DevInfo, data *windows.DevInfoData, property windows.SPDRP) string {
+	value, err := devInfo.DeviceRegistryProperty(data, property)
+	if err != nil {
+		return ""
+	}
+
+	s, _ := value.(string)
+	return s
+}
@@ -0,0 +1,88 @@
+//go:build windows
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * win_firmware.go
+ * ---
+ * Last Modified: 19/10/2026 11:59PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"encoding/binary"
+	"github.com/Inspect-Element-Ltd/vm/internal/firmware"
+	"golang.org/x/sys/windows"
+	"unsafe"
+)
+
+// Firmware table providers, the signature as a big-endian DWORD.
+const (
+	firmwareProviderACPI = 'A'<<24 | 'C'<<16 | 'P'<<8 | 'I'
+	firmwareProviderRSMB = 'R'<<24 | 'S'<<16 | 'M'<<8 | 'B'
+)
+
+var (
+	procEnumSystemFirmwareTables = windows.NewLazySystemDLL("kernel32.dll").NewProc("EnumSystemFirmwareTables")
+	procGetSystemFirmwareTable   = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetSystemFirmwareTable")
+)
+
+// Firmware checks the raw SMBIOS and ACPI tables, unlike the copies of the
+// SMBIOS strings under HARDWARE\Description\System these come straight from the firmware.
+func Firmware(r *Report) {
+	s := sigs()
+
+	if structures, err := firmware.ParseRawSMBIOSData(firmwareTable(firmwareProviderRSMB, 0)); err == nil {
+		s.matchSMBIOSTable(structures, r)
+	}
+
+	var headers []firmware.ACPIHeader
+	for _, id := range firmwareTableIDs(firmwareProviderACPI) {
+		if header, err := firmware.ParseACPIHeader(firmwareTable(firmwareProviderACPI, id)); err == nil {
+			headers = append(headers, header)
+		}
+	}
+	s.matchACPITables(headers, r)
+}
+
+// firmwareTable reads a table, nil if it doesn't exist.
+func firmwareTable(provider uint32, id uint32) []byte {
+	size, _, _ := procGetSystemFirmwareTable.Call(uintptr(provider), uintptr(id), 0, 0)
+	if size == 0 {
+		return nil
+	}
+
+	buf := make([]byte, size)
+	n, _, _ := procGetSystemFirmwareTable.Call(uintptr(provider), uintptr(id), uintptr(unsafe.Pointer(&buf[0])), size)
+	if n == 0 || n > size {
+		return nil
+	}
+
+	return buf[:n]
+}
+
+// firmwareTableIDs lists the tables of a provider, for ACPI the table
+// signatures in the byte order GetSystemFirmwareTable takes them.
+func firmwareTableIDs(provider uint32) []uint32 {
+	size, _, _ := procEnumSystemFirmwareTables.Call(uintptr(provider), 0, 0)
+	if size == 0 {
+		return nil
+	}
+
+	buf := make([]byte, size)
+	n, _, _ := procEnumSystemFirmwareTables.Call(uintptr(provider), uintptr(unsafe.Pointer(&buf[0])), size)
+	if n == 0 || n > size {
+		return nil
+	}
+
+	ids := make([]uint32, 0, n/4)
+	for i := 0; i+4 <= int(n); i += 4 {
+		ids = append(ids, binary.LittleEndian.Uint32(buf[i:]))
+	}
+
+	return ids
+}
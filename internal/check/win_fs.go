@@ -7,7 +7,7 @@
  *
  * win_fs.go
  * ---
- * Last Modified: 11/05/2024 01:15AM (BST)
+ * Last Modified: 19/10/2026 10:20PM (BST)
  * Modified By: Gianluca Oliva <hello@gian.sh>
  */
 
@@ -15,79 +15,76 @@ package check
 
 import (
 	"fmt"
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"golang.org/x/sys/windows"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 )
 
-var (
-	filesByVendor = map[string][]string{
-		"Parallels": {
-			`c:\windows\system32\drivers\prleth.sys`,
-			`c:\windows\system32\drivers\prlfs.sys`,
-			`c:\windows\system32\drivers\prlmouse.sys`,
-			`c:\windows\system32\drivers\prlvideo.sys`,
-			`c:\windows\system32\drivers\prltime.sys`,
-			`c:\windows\system32\drivers\prl_pv32.sys`,
-			`c:\windows\system32\drivers\prl_paravirt_32.sys`,
-		},
-		"VirtualBox": {
-			`c:\windows\system32\drivers\VBoxMouse.sys`,
-			`c:\windows\system32\drivers\VBoxGuest.sys`,
-			`c:\windows\system32\drivers\VBoxSF.sys`,
-			`c:\windows\system32\drivers\VBoxVideo.sys`,
-			`c:\windows\system32\vboxdisp.dll`,
-			`c:\windows\system32\vboxhook.dll`,
-			`c:\windows\system32\vboxmrxnp.dll`,
-			`c:\windows\system32\vboxogl.dll`,
-			`c:\windows\system32\vboxoglarrayspu.dll`,
-			`c:\windows\system32\vboxoglcrutil.dll`,
-			`c:\windows\system32\vboxoglerrorspu.dll`,
-			`c:\windows\system32\vboxoglfeedbackspu.dll`,
-			`c:\windows\system32\vboxoglpackspu.dll`,
-			`c:\windows\system32\vboxoglpassthroughspu.dll`,
-			`c:\windows\system32\vboxservice.exe`,
-			`c:\windows\system32\vboxtray.exe`,
-			`c:\windows\system32\VBoxControl.exe`,
-		},
-		"VirtualPC": {
-			`c:\windows\system32\drivers\vmsrvc.sys`,
-			`c:\windows\system32\drivers\vpc-s3.sys`,
-		},
-		"VMware": {
-			`c:\windows\system32\drivers\vmmouse.sys`,
-			`c:\windows\system32\drivers\vmnet.sys`,
-			`c:\windows\system32\drivers\vmxnet.sys`,
-			`c:\windows\system32\drivers\vmhgfs.sys`,
-			`c:\windows\system32\drivers\vmx86.sys`,
-			`c:\windows\system32\drivers\hgfs.sys`,
-		},
-		"VirtIO": {
-			`c:\program files\virtio-win\balloon\balloon.sys`,
-			`c:\program files\virtio-win\fwcfg\fwcfg.sys`,
-			`c:\program files\virtio-win\network\netkvm.sys`,
-			`c:\program files\virtio-win\pvpanic\pvpanic.sys`,
-			`c:\program files\virtio-win\qemupciserial\qemupciserial.sys`,
-			`c:\program files\virtio-win\viofs\viofs.sys`,
-			`c:\program files\virtio-win\viogpudo\viogpudo.sys`,
-			`c:\program files\virtio-win\vioinput\vioinput.sys`,
-			`c:\program files\virtio-win\viorng\viorng.sys`,
-			`c:\program files\virtio-win\vioscsi\vioscsi.sys`,
-			`c:\program files\virtio-win\vioserial\vioserial.sys`,
-			`c:\program files\virtio-win\viostor\viostor.sys`,
-		},
-		"QEMU": {
-			`c:\program files\qemu-ga\qemu-ga.exe`,
-		},
+// knownFolders resolves the folders file signatures start with, once.
+var knownFolders = sync.OnceValue(func() map[string]string {
+	folders := make(map[string]string)
+
+	if dir, err := windows.GetSystemWindowsDirectory(); err == nil {
+		folders["SystemRoot"] = dir
 	}
-)
 
-func FileSystem() (bool, string, string) {
-	for vendor, files := range filesByVendor {
-		for _, file := range files {
-			if _, err := os.Stat(file); err == nil {
-				return true, vendor, fmt.Sprintf("%s file exists", file)
+	if dir, err := windows.GetSystemDirectory(); err == nil {
+		folders["System32"] = dir
+		folders["SysNative"] = dir
+	}
+
+	// A 32-bit process on 64-bit Windows has System32 redirected to SysWOW64,
+	// Sysnative is the alias that gets to the real one.
+	var wow64 bool
+	if err := windows.IsWow64Process(windows.CurrentProcess(), &wow64); err == nil && wow64 && folders["SystemRoot"] != "" {
+		folders["SysNative"] = filepath.Join(folders["SystemRoot"], "Sysnative")
+	}
+
+	// ProgramW6432 is only set on 64-bit Windows, where a 32-bit process gets the x86 folder as ProgramFiles.
+	if dir := os.Getenv("ProgramW6432"); dir != "" {
+		folders["ProgramFiles"] = dir
+	} else if dir, err := windows.KnownFolderPath(windows.FOLDERID_ProgramFiles, windows.KF_FLAG_DEFAULT); err == nil {
+		folders["ProgramFiles"] = dir
+	}
+
+	if dir, err := windows.KnownFolderPath(windows.FOLDERID_ProgramFilesX86, windows.KF_FLAG_DEFAULT); err == nil {
+		folders["ProgramFiles(x86)"] = dir
+	}
+
+	return folders
+})
+
+func FileSystem(r *Report) {
+	s := sigs()
+	for _, vendor := range vendorNames(s.files) {
+		for _, file := range s.files[vendor] {
+			if fileExists(file) {
+				r.Add(Evidence{Class: ClassVM, Vendor: vendor, Reason: fmt.Sprintf("%s file exists", file)})
 			}
 		}
 	}
+}
+
+// fileExists resolves the known folder a file signature starts with and
+// checks for the file below it, ignoring case even in case-sensitive directories.
+func fileExists(file string) bool {
+	folder, rest, ok := signature.SplitKnownFolder(file)
+	if !ok {
+		return false
+	}
+
+	dir, ok := knownFolders()[folder]
+	if !ok {
+		return false
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, rest)); err == nil {
+		return true
+	}
 
-	return false, "", ""
+	_, found := findFold(os.DirFS(dir), strings.ReplaceAll(rest, `\`, "/"))
+	return found
 }
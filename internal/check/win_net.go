@@ -0,0 +1,241 @@
+//go:build windows
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * win_net.go
+ * ---
+ * Last Modified: 20/10/2026 01:50PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+	"net"
+	"regexp"
+	"strings"
+	"unsafe"
+)
+
+// GAA_FLAG_INCLUDE_GATEWAYS, missing from x/sys/windows.
+const gaaFlagIncludeGateways = 0x0080
+
+// The device class every network adapter is installed under.
+const netClassKey = `SYSTEM\CurrentControlSet\Control\Class\{4D36E972-E325-11CE-BFC1-08002BE10318}`
+
+var (
+	// PCI\VEN_8086&DEV_100E&SUBSYS_11001AF4&REV_03\...
+	pciInstanceID = regexp.MustCompile(`(?i)VEN_([0-9A-F]{4})&DEV_([0-9A-F]{4})(?:&SUBSYS_([0-9A-F]{4})([0-9A-F]{4}))?`)
+)
+
+// adapters returns the adapters GetAdaptersAddresses knows about.
+func adapters() []*windows.IpAdapterAddresses {
+	size := uint32(15000)
+	for {
+		buf := make([]byte, size)
+		first := (*windows.IpAdapterAddresses)(unsafe.Pointer(&buf[0]))
+
+		err := windows.GetAdaptersAddresses(windows.AF_UNSPEC, gaaFlagIncludeGateways, 0, first, &size)
+		if err == windows.ERROR_BUFFER_OVERFLOW {
+			continue
+		}
+		if err != nil {
+			return nil
+		}
+
+		var list []*windows.IpAdapterAddresses
+		for adapter := first; adapter != nil; adapter = adapter.Next {
+			list = append(list, adapter)
+		}
+
+		return list
+	}
+}
+
+func adapterFor(iface net.Interface) *windows.IpAdapterAddresses {
+	for _, adapter := range adapters() {
+		if int(adapter.IfIndex) == iface.Index {
+			return adapter
+		}
+	}
+
+	return nil
+}
+
+// defaultRouteInterface returns the first adapter with a default gateway.
+func defaultRouteInterface() string {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+
+	for _, adapter := range adapters() {
+		if adapter.FirstGatewayAddress == nil || adapter.OperStatus != windows.IfOperStatusUp {
+			continue
+		}
+
+		for _, iface := range ifaces {
+			if iface.Index == int(adapter.IfIndex) {
+				return iface.Name
+			}
+		}
+	}
+
+	return ""
+}
+
+// defaultGateway returns the first IPv4 gateway of an adapter that is up,
+// its MAC address comes from the ARP cache.
+func defaultGateway() (gateway, bool) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return gateway{}, false
+	}
+
+	for _, adapter := range adapters() {
+		if adapter.OperStatus != windows.IfOperStatusUp {
+			continue
+		}
+
+		for gw := adapter.FirstGatewayAddress; gw != nil; gw = gw.Next {
+			ip := gw.Address.IP().To4()
+			if ip == nil {
+				continue
+			}
+
+			for _, iface := range ifaces {
+				if iface.Index == int(adapter.IfIndex) {
+					return gateway{iface: iface.Name, address: interfaceIPv4(iface.Name), ip: ip, mac: neighbour(ip)}, true
+				}
+			}
+		}
+	}
+
+	return gateway{}, false
+}
+
+// mibIPNetRow is MIB_IPNETROW, one entry of the IPv4 ARP cache.
+type mibIPNetRow struct {
+	Index       uint32
+	PhysAddrLen uint32
+	PhysAddr    [8]byte
+	Addr        [4]byte
+	Type        uint32
+}
+
+var procGetIpNetTable = windows.NewLazySystemDLL("iphlpapi.dll").NewProc("GetIpNetTable")
+
+// neighbour looks up the MAC address of ip in the ARP cache without sending anything.
+func neighbour(ip net.IP) net.HardwareAddr {
+	var size uint32
+	procGetIpNetTable.Call(0, uintptr(unsafe.Pointer(&size)), 0)
+	if size == 0 {
+		return nil
+	}
+
+	buf := make([]byte, size)
+	if ret, _, _ := procGetIpNetTable.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)), 0); ret != 0 {
+		return nil
+	}
+
+	// MIB_IPNETTABLE is the entry count followed by the rows.
+	count := *(*uint32)(unsafe.Pointer(&buf[0]))
+	rows := unsafe.Slice((*mibIPNetRow)(unsafe.Pointer(&buf[4])), count)
+	for _, row := range rows {
+		if net.IP(row.Addr[:]).Equal(ip) && row.PhysAddrLen <= uint32(len(row.PhysAddr)) {
+			return net.HardwareAddr(row.PhysAddr[:row.PhysAddrLen])
+		}
+	}
+
+	return nil
+}
+
+// readAdapterInfo reads the adapter type and description Windows reports for
+// iface, which is how the host adapter check tells them apart.
+func readAdapterInfo(iface net.Interface) adapterInfo {
+	info := adapterInfo{name: iface.Name}
+
+	adapter := adapterFor(iface)
+	if adapter == nil {
+		return info
+	}
+
+	info.description = windows.UTF16PtrToString(adapter.Description)
+
+	// Tunnels and software loopbacks are never the machine's NIC.
+	if adapter.IfType == windows.IF_TYPE_TUNNEL || adapter.IfType == windows.IF_TYPE_SOFTWARE_LOOPBACK {
+		info.software = fmt.Sprintf("adapter type %d", adapter.IfType)
+	}
+
+	return info
+}
+
+// nics builds the interface inventory from the network adapter class key,
+// each adapter points at its device instance which holds the bound service.
+func nics() []nic {
+	class, err := registry.OpenKey(registry.LOCAL_MACHINE, netClassKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return nil
+	}
+	defer class.Close()
+
+	names, err := class.ReadSubKeyNames(-1)
+	if err != nil {
+		return nil
+	}
+
+	// Adapters are matched to their class key through the NetCfgInstanceId GUID.
+	byGUID := make(map[string]string)
+	for _, adapter := range adapters() {
+		byGUID[strings.ToUpper(windows.BytePtrToString(adapter.AdapterName))] = windows.UTF16PtrToString(adapter.FriendlyName)
+	}
+
+	var list []nic
+	for _, name := range names {
+		key, err := registry.OpenKey(class, name, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+
+		guid, _, _ := key.GetStringValue("NetCfgInstanceId")
+		instanceID, _, _ := key.GetStringValue("DeviceInstanceID")
+		key.Close()
+
+		friendlyName, ok := byGUID[strings.ToUpper(guid)]
+		if !ok || instanceID == "" {
+			continue
+		}
+
+		n := nic{name: friendlyName}
+		n.bus, _, _ = strings.Cut(instanceID, `\`)
+
+		if device, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Enum\`+instanceID, registry.QUERY_VALUE); err == nil {
+			n.driver, _, _ = device.GetStringValue("Service")
+			device.Close()
+		}
+
+		if match := pciInstanceID.FindStringSubmatch(instanceID); match != nil {
+			n.id = strings.ToLower(match[1] + ":" + match[2])
+			// SUBSYS_ is the subsystem device followed by the subsystem vendor.
+			if match[3] != "" {
+				n.subsystem = strings.ToLower(match[4] + ":" + match[3])
+			}
+		}
+
+		list = append(list, n)
+	}
+
+	return list
+}
+
+// isWired reports whether iface is an Ethernet adapter.
+func isWired(iface net.Interface) bool {
+	adapter := adapterFor(iface)
+	return adapter != nil && adapter.IfType == windows.IF_TYPE_ETHERNET_CSMACD
+}
@@ -0,0 +1,68 @@
+//go:build windows
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * win_objects.go
+ * ---
+ * Last Modified: 20/10/2026 01:20AM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"golang.org/x/sys/windows"
+	"strings"
+)
+
+// DeviceObjects checks for the devices and pipes guest tools create. Nothing
+// is opened, devices are looked up as DOS device links and pipes by listing
+// the pipe namespace, so no driver or pipe server ever sees a connection.
+func DeviceObjects(r *Report) {
+	pipes := namedPipes()
+
+	sigs().matchDeviceObjects(func(object string) bool {
+		if isPipe(object) {
+			return pipes[strings.ToLower(object[len(pipePrefix):])]
+		}
+
+		return dosDeviceExists(object[len(devicePrefix):])
+	}, r)
+}
+
+// dosDeviceExists reports whether \\.\name links to a device.
+func dosDeviceExists(name string) bool {
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return false
+	}
+
+	target := make([]uint16, windows.MAX_PATH)
+	n, err := windows.QueryDosDevice(namePtr, &target[0], uint32(len(target)))
+	return err == nil && n > 0
+}
+
+// namedPipes lists the pipes that exist, lowercased.
+func namedPipes() map[string]bool {
+	pipes := make(map[string]bool)
+
+	pattern, _ := windows.UTF16PtrFromString(pipePrefix + "*")
+	var data windows.Win32finddata
+	handle, err := windows.FindFirstFile(pattern, &data)
+	if err != nil {
+		return pipes
+	}
+	defer windows.FindClose(handle)
+
+	for {
+		pipes[strings.ToLower(windows.UTF16ToString(data.FileName[:]))] = true
+		if windows.FindNextFile(handle, &data) != nil {
+			break
+		}
+	}
+
+	return pipes
+}
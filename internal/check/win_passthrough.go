@@ -0,0 +1,101 @@
+//go:build windows
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * win_passthrough.go
+ * ---
+ * Last Modified: 20/10/2026 10:20AM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"golang.org/x/sys/windows"
+	"strings"
+)
+
+// Passthrough checks the device registry for the devices GPU passthrough VMs
+// are built with: an IVSHMEM device and an emulated secondary display next to
+// a physical GPU.
+func Passthrough(r *Report) {
+	s := sigs()
+
+	if key := pciEnumKey(s.ivshmemDevice); doesRegistryKeyExist(key) {
+		r.Add(Evidence{
+			Class:  ClassPassthrough,
+			Vendor: "QEMU",
+			Reason: fmt.Sprintf("IVSHMEM device %s found in Registry", key),
+			Observations: []Observation{
+				{Source: "PCI", Value: s.ivshmemDevice, Vendor: "QEMU", Virtual: true},
+			},
+		})
+	}
+
+	for _, key := range s.lookingGlassKeys {
+		if doesRegistryKeyExist(key) {
+			r.Add(Evidence{Class: ClassPassthrough, Vendor: "QEMU", Reason: fmt.Sprintf("%s found in Registry", key)})
+		}
+	}
+
+	// Only display adapters that are attached right now, the Enum key also holds
+	// removed ones and QEMU's Intel chipset devices share a vendor with GPUs.
+	gpu := ""
+	var displays []string
+	for _, device := range presentClassDevices(&displayClass) {
+		id, ok := pciHardwareID(device.hardwareIDs)
+		if !ok {
+			continue
+		}
+
+		if _, ok := s.virtualDisplays[id]; ok {
+			displays = append(displays, id)
+		} else if vendor, ok := s.gpuVendors[pciVendorID(id)]; ok && gpu == "" {
+			gpu = vendor
+		}
+	}
+
+	if gpu == "" {
+		return
+	}
+
+	for _, id := range displays {
+		r.Add(Evidence{
+			Class:  ClassPassthrough,
+			Vendor: "QEMU",
+			Reason: fmt.Sprintf("%s secondary display %s found next to %s GPU", s.virtualDisplays[id], id, gpu),
+		})
+	}
+}
+
+// displayClass is the setup class of display adapters, GUID_DEVCLASS_DISPLAY.
+var displayClass = windows.GUID{Data1: 0x4d36e968, Data2: 0xe325, Data3: 0x11ce, Data4: [8]byte{0xbf, 0xc1, 0x08, 0x00, 0x2b, 0xe1, 0x03, 0x18}}
+
+// pciHardwareID returns the vendor:device ID of a PCI device from its
+// hardware IDs, e.g. PCI\VEN_1B36&DEV_0100&SUBSYS_11001AF4 is 1b36:0100.
+func pciHardwareID(hardwareIDs []string) (string, bool) {
+	for _, hardwareID := range hardwareIDs {
+		rest, ok := strings.CutPrefix(strings.ToUpper(hardwareID), `PCI\VEN_`)
+		if !ok || len(rest) < 13 || rest[4:9] != "&DEV_" {
+			continue
+		}
+
+		return strings.ToLower(rest[:4] + ":" + rest[9:13]), true
+	}
+
+	return "", false
+}
+
+// pciEnumKey builds the wildcard device registry key for a vendor or vendor:device ID.
+func pciEnumKey(id string) string {
+	vendorID, deviceID, found := strings.Cut(strings.ToUpper(id), ":")
+	if !found {
+		return fmt.Sprintf(`HKLM\SYSTEM\CurrentControlSet\Enum\PCI\VEN_%s*`, vendorID)
+	}
+
+	return fmt.Sprintf(`HKLM\SYSTEM\CurrentControlSet\Enum\PCI\VEN_%s&DEV_%s*`, vendorID, deviceID)
+}
@@ -0,0 +1,45 @@
+//go:build windows
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * win_reg_test.go
+ * ---
+ * Last Modified: 20/10/2026 03:20PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"golang.org/x/sys/windows/registry"
+	"testing"
+)
+
+func TestExtractKeyTypeFrom(t *testing.T) {
+	tests := []struct {
+		registryKey string
+		keyType     registry.Key
+		keyPath     string
+		fails       bool
+	}{
+		{registryKey: `HKLM\SOFTWARE\Oracle`, keyType: registry.LOCAL_MACHINE, keyPath: `SOFTWARE\Oracle`},
+		{registryKey: `hklm\SOFTWARE\Oracle`, keyType: registry.LOCAL_MACHINE, keyPath: `SOFTWARE\Oracle`},
+		{registryKey: `Hku\.DEFAULT`, keyType: registry.USERS, keyPath: `.DEFAULT`},
+		{registryKey: `HKEY\SOFTWARE`, fails: true},
+		{registryKey: `HKLM`, fails: true},
+	}
+
+	for _, test := range tests {
+		keyType, keyPath, err := extractKeyTypeFrom(test.registryKey)
+		if (err != nil) != test.fails {
+			t.Errorf("%s: error is %v, expected one: %v", test.registryKey, err, test.fails)
+			continue
+		}
+		if !test.fails && (keyType != test.keyType || keyPath != test.keyPath) {
+			t.Errorf("%s is %v %q, expected %v %q", test.registryKey, keyType, keyPath, test.keyType, test.keyPath)
+		}
+	}
+}
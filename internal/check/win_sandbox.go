@@ -0,0 +1,61 @@
+//go:build windows
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * win_sandbox.go
+ * ---
+ * Last Modified: 20/10/2026 02:00AM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"golang.org/x/sys/windows"
+	"os/user"
+)
+
+// Sandbox checks for Windows Sandbox, Application Guard and Hyper-V isolated
+// containers, reported as VendorWindowsSandbox rather than Hyper-V.
+func Sandbox(r *Report) {
+	username := ""
+	if current, err := user.Current(); err == nil {
+		username = current.Username
+	}
+
+	matchSandbox(liveRegistry{}, username, queryServices(sandboxServices), r)
+}
+
+// queryServices reads the state of the named services, ones that aren't
+// installed are left out.
+func queryServices(names []string) []service {
+	handle, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_CONNECT)
+	if err != nil {
+		return nil
+	}
+	defer windows.CloseServiceHandle(handle)
+
+	var services []service
+	for _, name := range names {
+		namePtr, err := windows.UTF16PtrFromString(name)
+		if err != nil {
+			continue
+		}
+
+		serviceHandle, err := windows.OpenService(handle, namePtr, windows.SERVICE_QUERY_STATUS)
+		if err != nil {
+			continue
+		}
+
+		var status windows.SERVICE_STATUS
+		if windows.QueryServiceStatus(serviceHandle, &status) == nil {
+			services = append(services, service{name: name, state: serviceState(status.CurrentState)})
+		}
+		windows.CloseServiceHandle(serviceHandle)
+	}
+
+	return services
+}
@@ -0,0 +1,217 @@
+//go:build windows
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * win_services.go
+ * ---
+ * Last Modified: 19/10/2026 10:58PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc/mgr"
+	"path/filepath"
+	"strings"
+	"unsafe"
+)
+
+// Services checks the services and drivers the Service Control Manager knows
+// about for guest tools, whether they're running or just installed.
+func Services(r *Report) {
+	s := sigs()
+	s.matchServices(s.liveServices(), r)
+}
+
+// liveServices enumerates every service and driver, only the ones a signature
+// matches get their config and image signature read.
+func (s *signatureSet) liveServices() []service {
+	handle, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_CONNECT|windows.SC_MANAGER_ENUMERATE_SERVICE)
+	if err != nil {
+		return nil
+	}
+	m := &mgr.Mgr{Handle: handle}
+	defer m.Disconnect()
+
+	statuses, err := enumServices(handle)
+	if err != nil {
+		return nil
+	}
+
+	var services []service
+	for _, status := range statuses {
+		name := windows.UTF16PtrToString(status.ServiceName)
+		if s.serviceVendor(name) == "" {
+			continue
+		}
+
+		svc := service{
+			name:   name,
+			state:  serviceState(status.ServiceStatusProcess.CurrentState),
+			driver: status.ServiceStatusProcess.ServiceType&(windows.SERVICE_KERNEL_DRIVER|windows.SERVICE_FILE_SYSTEM_DRIVER) != 0,
+		}
+
+		imagePath := "no image"
+		if serviceHandle, err := windows.OpenService(handle, status.ServiceName, windows.SERVICE_QUERY_CONFIG); err == nil {
+			config, err := (&mgr.Service{Name: name, Handle: serviceHandle}).Config()
+			if err == nil && config.BinaryPathName != "" {
+				imagePath = serviceImagePath(config.BinaryPathName)
+				svc.signer = imageSigner(imagePath)
+			}
+			windows.CloseServiceHandle(serviceHandle)
+		}
+		svc.imagePath = imagePath
+
+		services = append(services, svc)
+	}
+
+	return services
+}
+
+// enumServices is mgr.ListServices, but for drivers too and keeping the status.
+func enumServices(handle windows.Handle) ([]windows.ENUM_SERVICE_STATUS_PROCESS, error) {
+	var err error
+	var bytesNeeded, servicesReturned uint32
+	var buf []byte
+	for {
+		var p *byte
+		if len(buf) > 0 {
+			p = &buf[0]
+		}
+
+		err = windows.EnumServicesStatusEx(handle, windows.SC_ENUM_PROCESS_INFO,
+			windows.SERVICE_DRIVER|windows.SERVICE_WIN32, windows.SERVICE_STATE_ALL,
+			p, uint32(len(buf)), &bytesNeeded, &servicesReturned, nil, nil)
+		if err == nil {
+			break
+		}
+		if err != windows.ERROR_MORE_DATA {
+			return nil, err
+		}
+		if bytesNeeded <= uint32(len(buf)) {
+			return nil, err
+		}
+		buf = make([]byte, bytesNeeded)
+	}
+	if servicesReturned == 0 {
+		return nil, nil
+	}
+
+	// The names point into buf, which the statuses keep alive.
+	return unsafe.Slice((*windows.ENUM_SERVICE_STATUS_PROCESS)(unsafe.Pointer(&buf[0])), int(servicesReturned)), nil
+}
+
+func serviceState(state uint32) string {
+	switch state {
+	case windows.SERVICE_RUNNING:
+		return "running"
+	case windows.SERVICE_STOPPED:
+		return "stopped"
+	case windows.SERVICE_START_PENDING:
+		return "starting"
+	case windows.SERVICE_STOP_PENDING:
+		return "stopping"
+	case windows.SERVICE_PAUSED, windows.SERVICE_PAUSE_PENDING, windows.SERVICE_CONTINUE_PENDING:
+		return "paused"
+	}
+
+	return "in an unknown state"
+}
+
+// serviceImagePath turns the command line the SCM stores into the path of the
+// image. Drivers are usually relative to SystemRoot, services can be quoted
+// and have arguments.
+func serviceImagePath(binaryPath string) string {
+	path := strings.TrimPrefix(strings.TrimSpace(binaryPath), `\??\`)
+
+	if quoted, ok := strings.CutPrefix(path, `"`); ok {
+		path, _, _ = strings.Cut(quoted, `"`)
+	} else if i := strings.Index(strings.ToLower(path), ".exe "); i != -1 {
+		path = path[:i+len(".exe")]
+	}
+
+	if expanded, err := registry.ExpandString(path); err == nil {
+		path = expanded
+	}
+
+	folders := knownFolders()
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasPrefix(lower, `\systemroot\`):
+		path = filepath.Join(folders["SystemRoot"], path[len(`\systemroot\`):])
+	case strings.HasPrefix(lower, `system32\`):
+		// SysNative so a 32-bit build isn't redirected to SysWOW64.
+		path = filepath.Join(folders["SysNative"], path[len(`system32\`):])
+	}
+
+	return path
+}
+
+// cmsgSignerInfo is the start of CMSG_SIGNER_INFO, enough to find the signer's certificate.
+type cmsgSignerInfo struct {
+	Version      uint32
+	Issuer       windows.CertNameBlob
+	SerialNumber windows.CryptIntegerBlob
+}
+
+const cmsgSignerInfoParam = 6
+
+var (
+	procCryptMsgGetParam = windows.NewLazySystemDLL("crypt32.dll").NewProc("CryptMsgGetParam")
+	procCryptMsgClose    = windows.NewLazySystemDLL("crypt32.dll").NewProc("CryptMsgClose")
+)
+
+// imageSigner returns the subject of the certificate that signed an image with
+// an embedded Authenticode signature. Inbox drivers are signed through
+// catalogs instead, those return an empty string.
+func imageSigner(path string) string {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return ""
+	}
+
+	var encoding, contentType, formatType uint32
+	var store, msg windows.Handle
+	err = windows.CryptQueryObject(windows.CERT_QUERY_OBJECT_FILE, unsafe.Pointer(pathPtr),
+		windows.CERT_QUERY_CONTENT_FLAG_PKCS7_SIGNED_EMBED, windows.CERT_QUERY_FORMAT_FLAG_BINARY,
+		0, &encoding, &contentType, &formatType, &store, &msg, nil)
+	if err != nil {
+		return ""
+	}
+	defer windows.CertCloseStore(store, 0)
+	defer procCryptMsgClose.Call(uintptr(msg))
+
+	var size uint32
+	if ret, _, _ := procCryptMsgGetParam.Call(uintptr(msg), cmsgSignerInfoParam, 0, 0, uintptr(unsafe.Pointer(&size))); ret == 0 || size < uint32(unsafe.Sizeof(cmsgSignerInfo{})) {
+		return ""
+	}
+
+	buf := make([]byte, size)
+	if ret, _, _ := procCryptMsgGetParam.Call(uintptr(msg), cmsgSignerInfoParam, 0, uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size))); ret == 0 {
+		return ""
+	}
+	signer := (*cmsgSignerInfo)(unsafe.Pointer(&buf[0]))
+
+	// The signer's certificate is found by its issuer and serial number.
+	find := windows.CertInfo{Issuer: signer.Issuer, SerialNumber: signer.SerialNumber}
+	cert, err := windows.CertFindCertificateInStore(store, windows.X509_ASN_ENCODING|windows.PKCS_7_ASN_ENCODING,
+		0, windows.CERT_FIND_SUBJECT_CERT, unsafe.Pointer(&find), nil)
+	if err != nil {
+		return ""
+	}
+	defer windows.CertFreeCertificateContext(cert)
+
+	name := make([]uint16, 256)
+	n := windows.CertGetNameString(cert, windows.CERT_NAME_SIMPLE_DISPLAY_TYPE, 0, nil, &name[0], uint32(len(name)))
+	if n <= 1 {
+		return ""
+	}
+
+	return windows.UTF16ToString(name[:n])
+}
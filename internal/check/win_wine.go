@@ -0,0 +1,65 @@
+//go:build windows
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * win_wine.go
+ * ---
+ * Last Modified: 20/10/2026 03:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"golang.org/x/sys/windows"
+	"os"
+	"unsafe"
+)
+
+// Only Wine's ntdll has these, they're how its own tools tell they run under Wine.
+var (
+	procWineGetVersion     = windows.NewLazySystemDLL("ntdll.dll").NewProc("wine_get_version")
+	procWineGetBuildID     = windows.NewLazySystemDLL("ntdll.dll").NewProc("wine_get_build_id")
+	procWineGetHostVersion = windows.NewLazySystemDLL("ntdll.dll").NewProc("wine_get_host_version")
+)
+
+// Wine checks whether the process runs under Wine, Proton or CrossOver and
+// reports the version and host OS along with it.
+func Wine(r *Report) {
+	wine := wineState{environment: make(map[string]string)}
+
+	if procWineGetVersion.Find() == nil {
+		version, _, _ := procWineGetVersion.Call()
+		wine.version = windows.BytePtrToString(*(**byte)(unsafe.Pointer(&version)))
+	}
+	if procWineGetBuildID.Find() == nil {
+		buildID, _, _ := procWineGetBuildID.Call()
+		wine.buildID = windows.BytePtrToString(*(**byte)(unsafe.Pointer(&buildID)))
+	}
+	if procWineGetHostVersion.Find() == nil {
+		var system, release *byte
+		procWineGetHostVersion.Call(uintptr(unsafe.Pointer(&system)), uintptr(unsafe.Pointer(&release)))
+		wine.hostSystem = windows.BytePtrToString(system)
+		wine.hostRelease = windows.BytePtrToString(release)
+	}
+
+	for _, names := range [][]string{wineEnvironment, protonEnvironment, crossOverEnvironment} {
+		for _, name := range names {
+			if value, ok := os.LookupEnv(name); ok {
+				wine.environment[name] = value
+			}
+		}
+	}
+
+	// Wine maps Z: to / by default, no Windows drive has these.
+	if _, err := os.Stat(`Z:\etc\passwd`); err == nil {
+		if info, err := os.Stat(`Z:\bin`); err == nil && info.IsDir() {
+			wine.unixRoot = true
+		}
+	}
+
+	sigs().matchWine(liveRegistry{}, wine, r)
+}
@@ -0,0 +1,208 @@
+//go:build windows
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * win_wmi.go
+ * ---
+ * Last Modified: 20/10/2026 03:25PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"golang.org/x/sys/windows"
+	"runtime"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// WMI checks the hardware WMI reports, the same classes support staff would look at.
+func WMI(r *Report) {
+	sigs().matchWMI(liveWMI{}, r)
+}
+
+var (
+	clsidWbemLocator = windows.GUID{Data1: 0x4590f811, Data2: 0x1d3a, Data3: 0x11d0, Data4: [8]byte{0x89, 0x1f, 0x00, 0xaa, 0x00, 0x4b, 0x2e, 0x24}}
+	iidIWbemLocator  = windows.GUID{Data1: 0xdc12a687, Data2: 0x737f, Data3: 0x11cf, Data4: [8]byte{0x88, 0x4d, 0x00, 0xaa, 0x00, 0x4b, 0x2e, 0x24}}
+
+	procCoCreateInstance  = windows.NewLazySystemDLL("ole32.dll").NewProc("CoCreateInstance")
+	procCoSetProxyBlanket = windows.NewLazySystemDLL("ole32.dll").NewProc("CoSetProxyBlanket")
+	procSysAllocString    = windows.NewLazySystemDLL("oleaut32.dll").NewProc("SysAllocString")
+	procSysFreeString     = windows.NewLazySystemDLL("oleaut32.dll").NewProc("SysFreeString")
+	procVariantClear      = windows.NewLazySystemDLL("oleaut32.dll").NewProc("VariantClear")
+)
+
+// Vtable slots of the methods used, after IUnknown's QueryInterface, AddRef and Release.
+const (
+	comRelease               = 2
+	wbemLocatorConnectServer = 3
+	wbemServicesExecQuery    = 20
+	enumWbemClassObjectNext  = 4
+	wbemClassObjectGet       = 4
+)
+
+const (
+	wbemFlagReturnImmediately = 0x10
+	wbemFlagForwardOnly       = 0x20
+	wbemInfinite              = 0xffffffff
+
+	clsctxInprocServer      = 0x1
+	rpcCAuthnWinNT          = 10
+	rpcCAuthnLevelCall      = 3
+	rpcCImpLevelImpersonate = 3
+)
+
+// VARIANT types WMI returns properties as, CIM uint64 and datetime come as strings.
+const (
+	vtI2   = 2
+	vtI4   = 3
+	vtBSTR = 8
+	vtBool = 11
+	vtI1   = 16
+	vtUI1  = 17
+	vtUI2  = 18
+	vtUI4  = 19
+	vtI8   = 20
+	vtUI8  = 21
+)
+
+// variant is VARIANT, 16 bytes on 32-bit and 24 on 64-bit Windows.
+type variant struct {
+	vt       uint16
+	reserved [3]uint16
+	val      uintptr
+	_        uintptr
+}
+
+// liveWMI queries the WMI service through COM, every query initialises COM on
+// the thread it's locked to so it works whatever the caller did.
+type liveWMI struct{}
+
+func (liveWMI) Query(namespace string, class string, properties []string) ([]wmiObject, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	switch err := windows.CoInitializeEx(0, windows.COINIT_MULTITHREADED); err {
+	case nil, syscall.Errno(windows.S_FALSE):
+		defer windows.CoUninitialize()
+	case syscall.Errno(windows.RPC_E_CHANGED_MODE):
+		// Already initialised as single-threaded, which works just as well.
+	default:
+		return nil, err
+	}
+
+	var locator unsafe.Pointer
+	if hr, _, _ := procCoCreateInstance.Call(uintptr(unsafe.Pointer(&clsidWbemLocator)), 0, clsctxInprocServer, uintptr(unsafe.Pointer(&iidIWbemLocator)), uintptr(unsafe.Pointer(&locator))); uint32(hr) != 0 {
+		return nil, fmt.Errorf("creating WbemLocator: %w", syscall.Errno(uint32(hr)))
+	}
+	defer comCall(locator, comRelease)
+
+	resource := sysAllocString(namespace)
+	defer procSysFreeString.Call(resource)
+
+	var services unsafe.Pointer
+	if hr := comCall(locator, wbemLocatorConnectServer, resource, 0, 0, 0, 0, 0, 0, uintptr(unsafe.Pointer(&services))); hr != 0 {
+		return nil, fmt.Errorf("connecting to %s: %w", namespace, syscall.Errno(hr))
+	}
+	defer comCall(services, comRelease)
+
+	if hr, _, _ := procCoSetProxyBlanket.Call(uintptr(services), rpcCAuthnWinNT, 0, 0, rpcCAuthnLevelCall, rpcCImpLevelImpersonate, 0, 0); uint32(hr) != 0 {
+		return nil, fmt.Errorf("setting proxy blanket: %w", syscall.Errno(uint32(hr)))
+	}
+
+	language := sysAllocString("WQL")
+	defer procSysFreeString.Call(language)
+	query := sysAllocString(wql(class, properties))
+	defer procSysFreeString.Call(query)
+
+	var enum unsafe.Pointer
+	if hr := comCall(services, wbemServicesExecQuery, language, query, wbemFlagForwardOnly|wbemFlagReturnImmediately, 0, uintptr(unsafe.Pointer(&enum))); hr != 0 {
+		return nil, fmt.Errorf("%s: %w", wql(class, properties), syscall.Errno(hr))
+	}
+	defer comCall(enum, comRelease)
+
+	var objects []wmiObject
+	for {
+		var object unsafe.Pointer
+		var returned uint32
+		hr := comCall(enum, enumWbemClassObjectNext, wbemInfinite, 1, uintptr(unsafe.Pointer(&object)), uintptr(unsafe.Pointer(&returned)))
+		if returned == 0 {
+			// WBEM_S_FALSE when there's nothing left, anything else is an error.
+			if hr != 0 && hr != uint32(windows.S_FALSE) {
+				return objects, fmt.Errorf("%s: %w", wql(class, properties), syscall.Errno(hr))
+			}
+			break
+		}
+
+		values := make(wmiObject)
+		for _, property := range properties {
+			if value, ok := wbemProperty(object, property); ok {
+				values[property] = value
+			}
+		}
+		comCall(object, comRelease)
+
+		objects = append(objects, values)
+	}
+
+	return objects, nil
+}
+
+// wbemProperty reads a property of an IWbemClassObject as a string.
+func wbemProperty(object unsafe.Pointer, name string) (string, bool) {
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return "", false
+	}
+
+	var v variant
+	if hr := comCall(object, wbemClassObjectGet, uintptr(unsafe.Pointer(namePtr)), 0, uintptr(unsafe.Pointer(&v)), 0, 0); hr != 0 {
+		return "", false
+	}
+	defer procVariantClear.Call(uintptr(unsafe.Pointer(&v)))
+
+	switch v.vt {
+	case vtBSTR:
+		return windows.UTF16PtrToString(*(**uint16)(unsafe.Pointer(&v.val))), true
+	case vtBool:
+		return strconv.FormatBool(int16(v.val) != 0), true
+	case vtI1:
+		return strconv.FormatInt(int64(int8(v.val)), 10), true
+	case vtI2:
+		return strconv.FormatInt(int64(int16(v.val)), 10), true
+	case vtI4:
+		return strconv.FormatInt(int64(int32(v.val)), 10), true
+	case vtUI1:
+		return strconv.FormatUint(uint64(uint8(v.val)), 10), true
+	case vtUI2:
+		return strconv.FormatUint(uint64(uint16(v.val)), 10), true
+	case vtUI4:
+		return strconv.FormatUint(uint64(uint32(v.val)), 10), true
+	case vtI8:
+		return strconv.FormatInt(*(*int64)(unsafe.Pointer(&v.val)), 10), true
+	case vtUI8:
+		return strconv.FormatUint(*(*uint64)(unsafe.Pointer(&v.val)), 10), true
+	}
+
+	// Null, empty and arrays.
+	return "", false
+}
+
+// comCall calls a method of a COM object by its vtable slot and returns the HRESULT.
+func comCall(object unsafe.Pointer, method int, args ...uintptr) uint32 {
+	vtable := *(**[32]uintptr)(object)
+	hr, _, _ := syscall.SyscallN(vtable[method], append([]uintptr{uintptr(object)}, args...)...)
+	return uint32(hr)
+}
+
+func sysAllocString(s string) uintptr {
+	ptr, _ := windows.UTF16PtrFromString(s)
+	bstr, _, _ := procSysAllocString.Call(uintptr(unsafe.Pointer(ptr)))
+	return bstr
+}
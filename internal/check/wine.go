@@ -0,0 +1,139 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * wine.go
+ * ---
+ * Last Modified: 20/10/2026 03:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Environment variables only Wine and the launchers built on it set.
+var (
+	wineEnvironment      = []string{"WINEPREFIX", "WINELOADER", "WINESERVER", "WINEDLLPATH", "WINEDLLOVERRIDES", "WINEDEBUG", "WINEESYNC", "WINEFSYNC"}
+	protonEnvironment    = []string{"STEAM_COMPAT_DATA_PATH", "STEAM_COMPAT_CLIENT_INSTALL_PATH", "PROTON_VERSION"}
+	crossOverEnvironment = []string{"CX_ROOT", "CX_BOTTLE"}
+)
+
+// Wine flavours, the vendor its evidence is reported as.
+const (
+	vendorWine      = "Wine"
+	vendorProton    = "Proton"
+	vendorCrossOver = "CrossOver"
+)
+
+// wineState is what the Wine check reads from the running process, anything
+// unknown is left empty, e.g. for hives.
+type wineState struct {
+	version     string // wine_get_version, e.g. 9.0.
+	buildID     string // wine_get_build_id, forks put their name in it, e.g. wine-8.0 (Proton-8.0-5).
+	hostSystem  string // wine_get_host_version, e.g. Linux or Darwin.
+	hostRelease string
+	environment map[string]string // Only the variables the check looks at.
+	unixRoot    bool              // Z: maps the Unix root directory.
+}
+
+// flavour returns which Wine this is going by the build ID and environment.
+func (w wineState) flavour() string {
+	build := strings.ToLower(w.buildID)
+	switch {
+	case strings.Contains(build, "proton"):
+		return vendorProton
+	case strings.Contains(build, "crossover"):
+		return vendorCrossOver
+	}
+
+	for _, name := range protonEnvironment {
+		if w.environment[name] != "" {
+			return vendorProton
+		}
+	}
+	for _, name := range crossOverEnvironment {
+		if w.environment[name] != "" {
+			return vendorCrossOver
+		}
+	}
+
+	return vendorWine
+}
+
+// describe returns the version and host, e.g. "Wine 9.0 on Linux 6.8.0".
+func (w wineState) describe(vendor string) string {
+	description := vendor
+	if w.version != "" {
+		description += " " + w.version
+	}
+	if w.buildID != "" && w.buildID != "wine-"+w.version {
+		description += fmt.Sprintf(" (%s)", w.buildID)
+	}
+	if w.hostSystem != "" {
+		description += " on " + strings.TrimSpace(w.hostSystem+" "+w.hostRelease)
+	}
+
+	return description
+}
+
+// matchWine checks for Wine and the layers built on it like Proton and
+// CrossOver. They aren't VMs, so it's reported as a compatibility layer.
+//
+// Anyone can set the environment variables, e.g. a Windows build tool
+// that also targets Wine, so they're weak unless ntdll or the keys agree.
+func (s *signatureSet) matchWine(reg registryReader, wine wineState, r *Report) {
+	vendor := wine.flavour()
+	found := wine.version != ""
+
+	if wine.version != "" {
+		r.Add(Evidence{
+			Class:  ClassCompatibilityLayer,
+			Vendor: vendor,
+			Reason: fmt.Sprintf("ntdll exports wine_get_version, %s", wine.describe(vendor)),
+		})
+	}
+
+	// Wine creates these in every prefix, though some launchers build prefixes without them.
+	for _, key := range s.wineKeys {
+		if registryKeyExists(reg, key) {
+			found = true
+			r.Add(Evidence{
+				Class:  ClassCompatibilityLayer,
+				Vendor: vendor,
+				Reason: fmt.Sprintf("%s found in Registry", key),
+			})
+		}
+	}
+
+	var names []string
+	for name := range wine.environment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if wine.environment[name] == "" {
+			continue
+		}
+
+		r.Add(Evidence{
+			Class:  ClassCompatibilityLayer,
+			Vendor: vendor,
+			Reason: fmt.Sprintf("Environment variable %s is set", name),
+			Weak:   !found,
+		})
+	}
+
+	if wine.unixRoot {
+		r.Add(Evidence{
+			Class:  ClassCompatibilityLayer,
+			Vendor: vendor,
+			Reason: "Drive Z: maps the Unix root directory",
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * wine_test.go
+ * ---
+ * Last Modified: 20/10/2026 03:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"testing"
+)
+
+func TestMatchWine(t *testing.T) {
+	file := testSignatures(t, map[string]signature.Vendor{})
+	file.CompatibilityLayers.RegistryKeys = []string{`HKCU\SOFTWARE\Wine`}
+	s := compile(file)
+
+	tests := []struct {
+		name     string
+		keys     []string
+		wine     wineState
+		vendor   string
+		evidence int
+		weak     int
+	}{
+		{
+			name:   "nothing",
+			wine:   wineState{environment: map[string]string{"WINEPREFIX": ""}},
+			vendor: "",
+		},
+		{
+			name:     "environment only",
+			wine:     wineState{environment: map[string]string{"WINEPREFIX": "/home/user/.wine", "WINEDEBUG": "-all"}},
+			vendor:   vendorWine,
+			evidence: 2,
+			weak:     2,
+		},
+		{
+			name:     "environment and key",
+			keys:     []string{`HKCU\SOFTWARE\Wine`},
+			wine:     wineState{environment: map[string]string{"STEAM_COMPAT_DATA_PATH": "/steam/compatdata/1"}},
+			vendor:   vendorProton,
+			evidence: 2,
+		},
+		{
+			name:     "environment and wine_get_version",
+			wine:     wineState{version: "9.0", environment: map[string]string{"CX_BOTTLE": "Steam"}},
+			vendor:   vendorCrossOver,
+			evidence: 2,
+		},
+		{
+			name:     "key not in the signatures",
+			keys:     []string{`HKLM\SOFTWARE\Wine`},
+			wine:     wineState{environment: map[string]string{"WINEPREFIX": "/home/user/.wine"}},
+			vendor:   vendorWine,
+			evidence: 1,
+			weak:     1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &Report{}
+			s.matchWine(snapshotRegistry(&Snapshot{RegistryKeys: test.keys}), test.wine, r)
+
+			if len(r.Evidence) != test.evidence {
+				t.Fatalf("got %d evidence, expected %d: %+v", len(r.Evidence), test.evidence, r.Evidence)
+			}
+
+			weak := 0
+			for _, e := range r.Evidence {
+				if e.Class != ClassCompatibilityLayer || e.Vendor != test.vendor {
+					t.Errorf("evidence is %s %s, expected %s %s", e.Class, e.Vendor, ClassCompatibilityLayer, test.vendor)
+				}
+				if e.Weak {
+					weak++
+				}
+			}
+			if weak != test.weak {
+				t.Errorf("%d evidence is weak, expected %d: %+v", weak, test.weak, r.Evidence)
+			}
+		})
+	}
+}
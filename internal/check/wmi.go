@@ -0,0 +1,150 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * wmi.go
+ * ---
+ * Last Modified: 20/10/2026 03:25PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// wmiObject is an instance returned by a WMI query, property -> value as a string.
+type wmiObject map[string]string
+
+// wmiSource runs WMI queries, either against the live WMI service on Windows
+// or a fake built from a snapshot.
+type wmiSource interface {
+	// Query returns the given properties of every instance of a class.
+	Query(namespace string, class string, properties []string) ([]wmiObject, error)
+}
+
+// wmiQuery is a class the checks read and how each of its properties is matched.
+type wmiQuery struct {
+	namespace  string
+	class      string
+	properties map[string]wmiMatch
+}
+
+type wmiMatch int
+
+const (
+	wmiSMBIOS     wmiMatch = iota // Matched against the SMBIOS vendor strings.
+	wmiHardwareID                 // A PNP device ID, matched like hardware IDs.
+)
+
+const (
+	wmiCIMV2 = `root\CIMV2`
+	wmiWMI   = `root\WMI`
+)
+
+var wmiQueries = []wmiQuery{
+	{wmiCIMV2, "Win32_ComputerSystem", map[string]wmiMatch{"Manufacturer": wmiSMBIOS, "Model": wmiSMBIOS}},
+	{wmiCIMV2, "Win32_BIOS", map[string]wmiMatch{"Manufacturer": wmiSMBIOS, "SerialNumber": wmiSMBIOS, "SMBIOSBIOSVersion": wmiSMBIOS}},
+	{wmiCIMV2, "Win32_BaseBoard", map[string]wmiMatch{"Manufacturer": wmiSMBIOS, "Product": wmiSMBIOS}},
+	{wmiCIMV2, "Win32_DiskDrive", map[string]wmiMatch{"Model": wmiSMBIOS, "PNPDeviceID": wmiHardwareID}},
+	{wmiCIMV2, "Win32_VideoController", map[string]wmiMatch{"Name": wmiSMBIOS, "AdapterCompatibility": wmiSMBIOS, "PNPDeviceID": wmiHardwareID}},
+}
+
+// Hypervisors rarely emulate thermal zones, though plenty of desktops don't expose one either.
+const (
+	wmiThermalZone         = "MSAcpi_ThermalZoneTemperature"
+	wmiThermalZoneProperty = "CurrentTemperature"
+)
+
+var errWMIClassNotFound = errors.New("WMI class not found")
+
+// wql returns the query a live source runs for q, the evidence names it so
+// it can be run by hand.
+func (q wmiQuery) wql() string {
+	return wql(q.class, vendorNames(q.properties))
+}
+
+func wql(class string, properties []string) string {
+	return fmt.Sprintf("SELECT %s FROM %s", strings.Join(properties, ", "), class)
+}
+
+// matchWMI checks the hardware WMI reports.
+func (s *signatureSet) matchWMI(wmi wmiSource, r *Report) {
+	var system []wmiObject
+	for _, q := range wmiQueries {
+		objects, err := wmi.Query(q.namespace, q.class, vendorNames(q.properties))
+		if err != nil {
+			continue
+		}
+		if q.class == "Win32_ComputerSystem" {
+			system = objects
+		}
+
+		for _, object := range objects {
+			for _, property := range vendorNames(q.properties) {
+				value := strings.TrimSpace(object[property])
+				if value == "" {
+					continue
+				}
+
+				vendor := ""
+				switch q.properties[property] {
+				case wmiSMBIOS:
+					vendor = s.smbiosVendor(value)
+				case wmiHardwareID:
+					vendor = s.hardwareIDVendor(value)
+				}
+				if vendor == "" {
+					continue
+				}
+
+				r.Add(Evidence{
+					Class:  ClassVM,
+					Vendor: vendor,
+					Reason: fmt.Sprintf("WMI %s.%s is %s (%s)", q.class, property, value, q.wql()),
+					Observations: []Observation{
+						{Source: "WMI " + q.class, Value: value, Vendor: vendor, Virtual: true},
+					},
+				})
+			}
+		}
+	}
+
+	// The manufacturer is a physical claim unless the same class gave a hypervisor away,
+	// a virtual disk or display adapter behind it is exactly what cloaking looks like.
+	if len(system) > 0 {
+		manufacturer := system[0]["Manufacturer"]
+		if manufacturer != "" && !s.isSMBIOSPlaceholder(manufacturer) && s.smbiosVendor(manufacturer) == "" && s.smbiosVendor(system[0]["Model"]) == "" {
+			r.Observe(Observation{Source: "WMI Win32_ComputerSystem", Value: manufacturer, Vendor: manufacturer})
+		}
+	}
+
+	// Only a hint, it's missing on a lot of real hardware too. The class needs
+	// admin rights, so only an answer with no instances counts, not an error.
+	if objects, err := wmi.Query(wmiWMI, wmiThermalZone, []string{wmiThermalZoneProperty}); err == nil && len(objects) == 0 {
+		r.Add(Evidence{
+			Class:  ClassVM,
+			Vendor: "Generic",
+			Reason: fmt.Sprintf("WMI %s has no instances (%s)", wmiThermalZone, wql(wmiThermalZone, []string{wmiThermalZoneProperty})),
+			Weak:   true,
+		})
+	}
+}
+
+// memoryWMI is WMI held in memory, keyed by class. It's what snapshots are
+// replayed against.
+type memoryWMI map[string][]wmiObject
+
+func (m memoryWMI) Query(namespace string, class string, properties []string) ([]wmiObject, error) {
+	for name, objects := range m {
+		if strings.EqualFold(name, class) {
+			return objects, nil
+		}
+	}
+
+	return nil, errWMIClassNotFound
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * wmi_test.go
+ * ---
+ * Last Modified: 20/10/2026 03:25PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package check
+
+import (
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"testing"
+)
+
+// countingWMI counts the queries run per class.
+type countingWMI struct {
+	memoryWMI
+	queries map[string]int
+}
+
+func (c *countingWMI) Query(namespace string, class string, properties []string) ([]wmiObject, error) {
+	c.queries[class]++
+	return c.memoryWMI.Query(namespace, class, properties)
+}
+
+func TestMatchWMIComputerSystem(t *testing.T) {
+	s := compile(testSignatures(t, map[string]signature.Vendor{
+		"VMware": {SMBIOS: []string{"VMware"}},
+	}))
+
+	tests := []struct {
+		name     string
+		system   wmiObject
+		physical bool
+	}{
+		{name: "physical", system: wmiObject{"Manufacturer": "Dell Inc.", "Model": "OptiPlex 7090"}, physical: true},
+		{name: "virtual model", system: wmiObject{"Manufacturer": "Dell Inc.", "Model": "VMware Virtual Platform"}},
+		{name: "no manufacturer", system: wmiObject{"Model": "OptiPlex 7090"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wmi := &countingWMI{memoryWMI{"Win32_ComputerSystem": {test.system}}, map[string]int{}}
+			r := &Report{}
+			s.matchWMI(wmi, r)
+
+			if n := wmi.queries["Win32_ComputerSystem"]; n != 1 {
+				t.Errorf("Win32_ComputerSystem was queried %d times", n)
+			}
+
+			physical := false
+			for _, o := range r.Observations {
+				physical = physical || (o.Source == "WMI Win32_ComputerSystem" && !o.Virtual)
+			}
+			if physical != test.physical {
+				t.Errorf("physical is %v, expected %v: %+v", physical, test.physical, r.Observations)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * acpi.go
+ * ---
+ * Last Modified: 19/10/2026 11:59PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package firmware
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+// Every ACPI table except FACS starts with this header.
+const acpiHeaderSize = 36
+
+// ACPIHeader is the header of an ACPI table, the IDs say who built the firmware.
+type ACPIHeader struct {
+	Signature       string // e.g. "DSDT" or "FACP".
+	Length          uint32
+	Revision        uint8
+	OEMID           string
+	OEMTableID      string
+	OEMRevision     uint32
+	CreatorID       string
+	CreatorRevision uint32
+}
+
+// ParseACPIHeader decodes the header at the start of an ACPI table, the IDs
+// are padded with spaces or NULs which are trimmed.
+func ParseACPIHeader(table []byte) (ACPIHeader, error) {
+	if len(table) < acpiHeaderSize {
+		return ACPIHeader{}, ErrTruncated
+	}
+
+	return ACPIHeader{
+		Signature:       acpiString(table[0:4]),
+		Length:          binary.LittleEndian.Uint32(table[4:]),
+		Revision:        table[8],
+		OEMID:           acpiString(table[10:16]),
+		OEMTableID:      acpiString(table[16:24]),
+		OEMRevision:     binary.LittleEndian.Uint32(table[24:]),
+		CreatorID:       acpiString(table[28:32]),
+		CreatorRevision: binary.LittleEndian.Uint32(table[32:]),
+	}, nil
+}
+
+func acpiString(b []byte) string {
+	return strings.TrimRight(string(b), " \x00")
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * smbios.go
+ * ---
+ * Last Modified: 19/10/2026 11:59PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+// Package firmware decodes raw SMBIOS and ACPI tables, however they were read,
+// e.g. GetSystemFirmwareTable on Windows or /sys/firmware on Linux.
+package firmware
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+// SMBIOS structure types the fields are read from.
+const (
+	TypeBIOS    = 0
+	TypeSystem  = 1
+	TypeBoard   = 2
+	TypeChassis = 3
+	TypeEnd     = 127
+)
+
+// Set in BIOS characteristics extension byte 2 when the firmware describes a virtual machine.
+const biosVirtualMachine = 1 << 4
+
+var ErrTruncated = errors.New("table is truncated")
+
+// Structure is a single SMBIOS structure, its formatted area and the strings after it.
+type Structure struct {
+	Type    uint8
+	Handle  uint16
+	Data    []byte // Formatted area including the 4 byte header.
+	Strings []string
+}
+
+// String returns the string the byte at offset in the formatted area refers
+// to, strings are numbered from 1 and 0 means none.
+func (s Structure) String(offset int) string {
+	if offset >= len(s.Data) {
+		return ""
+	}
+
+	index := int(s.Data[offset])
+	if index == 0 || index > len(s.Strings) {
+		return ""
+	}
+
+	return strings.TrimSpace(s.Strings[index-1])
+}
+
+// ParseSMBIOS splits a raw SMBIOS structure table into its structures, up to
+// the end-of-table structure.
+func ParseSMBIOS(table []byte) ([]Structure, error) {
+	var structures []Structure
+
+	for len(table) >= 4 {
+		length := int(table[1])
+		if length < 4 || length > len(table) {
+			return structures, ErrTruncated
+		}
+
+		s := Structure{
+			Type:   table[0],
+			Handle: binary.LittleEndian.Uint16(table[2:]),
+			Data:   table[:length],
+		}
+
+		// The string set ends with two NULs, a structure without strings has just those.
+		rest := table[length:]
+		end := strings.Index(string(rest), "\x00\x00")
+		if end == -1 {
+			return structures, ErrTruncated
+		}
+		for _, str := range strings.Split(string(rest[:end]), "\x00") {
+			if str != "" {
+				s.Strings = append(s.Strings, str)
+			}
+		}
+
+		structures = append(structures, s)
+		table = rest[end+2:]
+
+		if s.Type == TypeEnd {
+			break
+		}
+	}
+
+	return structures, nil
+}
+
+// ParseRawSMBIOSData parses what GetSystemFirmwareTable returns for 'RSMB', a
+// RawSMBIOSData header with the SMBIOS version followed by the structure table.
+func ParseRawSMBIOSData(data []byte) ([]Structure, error) {
+	if len(data) < 8 {
+		return nil, ErrTruncated
+	}
+
+	length := int(binary.LittleEndian.Uint32(data[4:]))
+	if 8+length > len(data) {
+		return nil, ErrTruncated
+	}
+
+	return ParseSMBIOS(data[8 : 8+length])
+}
+
+// Field is a decoded SMBIOS string, named like its file in /sys/class/dmi/id.
+type Field struct {
+	Name  string
+	Value string
+}
+
+var fieldOffsets = []struct {
+	name   string
+	typ    uint8
+	offset int
+}{
+	{"bios_vendor", TypeBIOS, 0x04},
+	{"bios_version", TypeBIOS, 0x05},
+	{"sys_vendor", TypeSystem, 0x04},
+	{"product_name", TypeSystem, 0x05},
+	{"product_version", TypeSystem, 0x06},
+	{"board_vendor", TypeBoard, 0x04},
+	{"board_name", TypeBoard, 0x05},
+	{"chassis_vendor", TypeChassis, 0x04},
+}
+
+// Fields decodes the identifying strings of the BIOS, system, board and
+// chassis structures, empty ones are left out.
+func Fields(structures []Structure) []Field {
+	var fields []Field
+
+	for _, f := range fieldOffsets {
+		for _, s := range structures {
+			if s.Type != f.typ {
+				continue
+			}
+
+			if value := s.String(f.offset); value != "" {
+				fields = append(fields, Field{Name: f.name, Value: value})
+			}
+			break
+		}
+	}
+
+	return fields
+}
+
+// FieldValue returns the value of the named field, if present.
+func FieldValue(fields []Field, name string) string {
+	for _, f := range fields {
+		if f.Name == name {
+			return f.Value
+		}
+	}
+
+	return ""
+}
+
+// IsVirtualMachine reports whether the BIOS structure flags the system as a
+// virtual machine, which SMBIOS 2.4 and up has a characteristics bit for.
+func IsVirtualMachine(structures []Structure) bool {
+	for _, s := range structures {
+		// Extension byte 2 sits at 0x13, after the first extension byte.
+		if s.Type == TypeBIOS && len(s.Data) > 0x13 {
+			return s.Data[0x13]&biosVirtualMachine != 0
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,381 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * regf.go
+ * ---
+ * Last Modified: 20/10/2026 12:10PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+// Package regf reads Windows registry hive files, e.g. SYSTEM, SOFTWARE or
+// NTUSER.DAT pulled from a disk image, without needing Windows.
+//
+// It's read-only and only reads the primary file, changes still sitting in
+// the .LOG1/.LOG2 transaction logs of a dirty hive aren't applied.
+package regf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	baseBlockSize = 4096
+
+	// Set in the data size when the data, at most 4 bytes, is stored in the data offset itself.
+	inlineDataFlag = 0x80000000
+
+	// Data bigger than this is split into segments behind a "db" record.
+	bigDataSegmentSize = 16344
+
+	keyCompressedName   = 0x0020
+	valueCompressedName = 0x0001
+)
+
+var (
+	ErrNotHive  = errors.New("not a registry hive")
+	ErrNotFound = errors.New("not found")
+)
+
+// Hive is a parsed hive file.
+type Hive struct {
+	data []byte
+	root uint32
+}
+
+// Key is a key in a hive.
+type Key struct {
+	hive *Hive
+	Name string
+
+	subKeyCount uint32
+	subKeyList  uint32
+	valueCount  uint32
+	valueList   uint32
+}
+
+// Value is a value of a key with its raw data, Type is the Windows value type, e.g. 1 for REG_SZ.
+type Value struct {
+	Name string
+	Type uint32
+	Data []byte
+}
+
+// Open reads a hive file.
+func Open(path string) (*Hive, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+// Parse reads a hive from its raw contents.
+func Parse(data []byte) (*Hive, error) {
+	if len(data) < baseBlockSize || !bytes.Equal(data[:4], []byte("regf")) {
+		return nil, ErrNotHive
+	}
+
+	return &Hive{data: data, root: binary.LittleEndian.Uint32(data[0x24:])}, nil
+}
+
+// Root returns the root key of the hive.
+func (h *Hive) Root() (*Key, error) {
+	return h.key(h.root)
+}
+
+// cell returns the data of the cell at offset, offsets are relative to the first hive bin.
+func (h *Hive) cell(offset uint32) ([]byte, error) {
+	start := baseBlockSize + int64(offset)
+	if start+4 > int64(len(h.data)) {
+		return nil, fmt.Errorf("cell %#x is outside the hive", offset)
+	}
+
+	size := int64(int32(binary.LittleEndian.Uint32(h.data[start:])))
+	if size < 0 {
+		// Allocated cells have a negative size.
+		size = -size
+	}
+	if size < 4 || start+size > int64(len(h.data)) {
+		return nil, fmt.Errorf("cell %#x has an invalid size", offset)
+	}
+
+	return h.data[start+4 : start+size], nil
+}
+
+func (h *Hive) key(offset uint32) (*Key, error) {
+	nk, err := h.cell(offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(nk) < 0x4c || !bytes.Equal(nk[:2], []byte("nk")) {
+		return nil, fmt.Errorf("cell %#x isn't a key", offset)
+	}
+
+	flags := binary.LittleEndian.Uint16(nk[0x02:])
+	nameLength := int(binary.LittleEndian.Uint16(nk[0x48:]))
+	if 0x4c+nameLength > len(nk) {
+		return nil, fmt.Errorf("key %#x has an invalid name", offset)
+	}
+
+	return &Key{
+		hive:        h,
+		Name:        decodeName(nk[0x4c:0x4c+nameLength], flags&keyCompressedName != 0),
+		subKeyCount: binary.LittleEndian.Uint32(nk[0x14:]),
+		subKeyList:  binary.LittleEndian.Uint32(nk[0x1c:]),
+		valueCount:  binary.LittleEndian.Uint32(nk[0x24:]),
+		valueList:   binary.LittleEndian.Uint32(nk[0x28:]),
+	}, nil
+}
+
+// SubKeys returns every subkey of the key.
+func (k *Key) SubKeys() ([]*Key, error) {
+	if k.subKeyCount == 0 {
+		return nil, nil
+	}
+
+	offsets, err := k.hive.subKeyOffsets(k.subKeyList, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*Key, 0, len(offsets))
+	for _, offset := range offsets {
+		key, err := k.hive.key(offset)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// Index roots can point at other index roots, but never this deep in a valid hive.
+const maxListDepth = 8
+
+// subKeyOffsets reads a subkey list, either a leaf (li, lf, lh) or an index root (ri) of leaves.
+func (h *Hive) subKeyOffsets(offset uint32, depth int) ([]uint32, error) {
+	if depth > maxListDepth {
+		return nil, errors.New("subkey lists are nested too deep")
+	}
+
+	list, err := h.cell(offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) < 4 {
+		return nil, fmt.Errorf("cell %#x isn't a subkey list", offset)
+	}
+
+	count := int(binary.LittleEndian.Uint16(list[2:]))
+	stride := 4
+	switch string(list[:2]) {
+	case "lf", "lh":
+		// Each offset is followed by a name hint or hash.
+		stride = 8
+	case "li", "ri":
+	default:
+		return nil, fmt.Errorf("cell %#x isn't a subkey list", offset)
+	}
+	if 4+count*stride > len(list) {
+		return nil, fmt.Errorf("subkey list %#x is truncated", offset)
+	}
+
+	var offsets []uint32
+	for i := 0; i < count; i++ {
+		entry := binary.LittleEndian.Uint32(list[4+i*stride:])
+		if string(list[:2]) != "ri" {
+			offsets = append(offsets, entry)
+			continue
+		}
+
+		leaf, err := h.subKeyOffsets(entry, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		offsets = append(offsets, leaf...)
+	}
+
+	return offsets, nil
+}
+
+// SubKey returns the subkey with the given name, ignoring case like Windows does.
+func (k *Key) SubKey(name string) (*Key, error) {
+	keys, err := k.SubKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if strings.EqualFold(key.Name, name) {
+			return key, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// Open returns the key at a backslash separated path below this one.
+func (k *Key) Open(path string) (*Key, error) {
+	key := k
+	for _, name := range strings.Split(strings.Trim(path, `\`), `\`) {
+		if name == "" {
+			continue
+		}
+
+		var err error
+		if key, err = key.SubKey(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+// Values returns every value of the key.
+func (k *Key) Values() ([]*Value, error) {
+	if k.valueCount == 0 {
+		return nil, nil
+	}
+
+	list, err := k.hive.cell(k.valueList)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(k.valueCount)*4 > uint64(len(list)) {
+		return nil, fmt.Errorf("value list %#x is truncated", k.valueList)
+	}
+
+	values := make([]*Value, 0, k.valueCount)
+	for i := 0; i < int(k.valueCount); i++ {
+		value, err := k.hive.value(binary.LittleEndian.Uint32(list[i*4:]))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// Value returns the value with the given name, ignoring case.
+func (k *Key) Value(name string) (*Value, error) {
+	values, err := k.Values()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range values {
+		if strings.EqualFold(value.Name, name) {
+			return value, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+func (h *Hive) value(offset uint32) (*Value, error) {
+	vk, err := h.cell(offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(vk) < 0x14 || !bytes.Equal(vk[:2], []byte("vk")) {
+		return nil, fmt.Errorf("cell %#x isn't a value", offset)
+	}
+
+	nameLength := int(binary.LittleEndian.Uint16(vk[0x02:]))
+	size := binary.LittleEndian.Uint32(vk[0x04:])
+	dataOffset := binary.LittleEndian.Uint32(vk[0x08:])
+	flags := binary.LittleEndian.Uint16(vk[0x10:])
+	if 0x14+nameLength > len(vk) {
+		return nil, fmt.Errorf("value %#x has an invalid name", offset)
+	}
+
+	value := &Value{
+		Name: decodeName(vk[0x14:0x14+nameLength], flags&valueCompressedName != 0),
+		Type: binary.LittleEndian.Uint32(vk[0x0c:]),
+	}
+
+	if size&inlineDataFlag != 0 {
+		size &^= inlineDataFlag
+		if size > 4 {
+			return nil, fmt.Errorf("value %#x has invalid inline data", offset)
+		}
+		value.Data = vk[0x08 : 0x08+size]
+		return value, nil
+	}
+
+	if value.Data, err = h.valueData(dataOffset, size); err != nil {
+		return nil, fmt.Errorf("value %q: %w", value.Name, err)
+	}
+
+	return value, nil
+}
+
+// valueData reads size bytes of value data, following a "db" record for big values.
+func (h *Hive) valueData(offset uint32, size uint32) ([]byte, error) {
+	if size == 0 {
+		return nil, nil
+	}
+
+	cell, err := h.cell(offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if size > bigDataSegmentSize && len(cell) >= 8 && bytes.Equal(cell[:2], []byte("db")) {
+		count := int(binary.LittleEndian.Uint16(cell[2:]))
+		segments, err := h.cell(binary.LittleEndian.Uint32(cell[4:]))
+		if err != nil {
+			return nil, err
+		}
+		if count*4 > len(segments) {
+			return nil, errors.New("big data segment list is truncated")
+		}
+
+		// The size can't be trusted for the allocation, it's at most what the segments hold.
+		data := make([]byte, 0, min(uint64(size), uint64(count)*bigDataSegmentSize))
+		for i := 0; i < count && uint32(len(data)) < size; i++ {
+			segment, err := h.cell(binary.LittleEndian.Uint32(segments[i*4:]))
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, segment[:min(len(segment), bigDataSegmentSize, int(size)-len(data))]...)
+		}
+
+		return data, nil
+	}
+
+	if int(size) > len(cell) {
+		return nil, errors.New("data is truncated")
+	}
+
+	return cell[:size], nil
+}
+
+// decodeName decodes a key or value name, stored as Latin-1 when compressed and UTF-16LE otherwise.
+func decodeName(name []byte, compressed bool) string {
+	if compressed {
+		runes := make([]rune, len(name))
+		for i, b := range name {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	}
+
+	chars := make([]uint16, len(name)/2)
+	for i := range chars {
+		chars[i] = binary.LittleEndian.Uint16(name[i*2:])
+	}
+
+	return string(utf16.Decode(chars))
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * regf_test.go
+ * ---
+ * Last Modified: 20/10/2026 12:10PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package regf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"os"
+	"slices"
+	"testing"
+	"unicode/utf16"
+)
+
+// testdata/test.hiv is written by testdata/mkhive.go, which describes its layout.
+func readTestHive(t *testing.T) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/test.hiv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func testRoot(t *testing.T, data []byte) *Key {
+	t.Helper()
+
+	hive, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := hive.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return root
+}
+
+func TestSubKeys(t *testing.T) {
+	root := testRoot(t, readTestHive(t))
+	if root.Name != "ROOT" {
+		t.Errorf("root is %q", root.Name)
+	}
+
+	// The root's subkeys sit behind an ri of an lf and an lh.
+	keys, err := root.SubKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, key := range keys {
+		names = append(names, key.Name)
+	}
+	if expected := []string{"Alpha", "Beta", "Γάμμα"}; !slices.Equal(names, expected) {
+		t.Errorf("subkeys are %q, expected %q", names, expected)
+	}
+
+	// Alpha's through an li, names are matched ignoring case.
+	delta, err := root.Open(`alpha\DELTA`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delta.Name != "Delta" {
+		t.Errorf("opened %q", delta.Name)
+	}
+
+	if _, err := root.Open(`Alpha\Missing`); !errors.Is(err, ErrNotFound) {
+		t.Errorf("opening a missing key returned %v", err)
+	}
+}
+
+func TestValues(t *testing.T) {
+	root := testRoot(t, readTestHive(t))
+
+	inline, err := root.Value("inline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inline.Type != 4 || len(inline.Data) != 4 || binary.LittleEndian.Uint32(inline.Data) != 0x12345678 {
+		t.Errorf("inline value is type %d, %x", inline.Type, inline.Data)
+	}
+
+	short, err := root.Value("Short")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := decodeUTF16(short.Data); short.Type != 1 || s != "hello\x00" {
+		t.Errorf("short value is type %d, %q", short.Type, s)
+	}
+
+	// Bigger than a single segment, so it's behind a db record.
+	big, err := root.Value("Big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(big.Data) != 20000 {
+		t.Fatalf("big value is %d bytes, expected 20000", len(big.Data))
+	}
+	for i, b := range big.Data {
+		if b != byte(i%251) {
+			t.Fatalf("big value differs at byte %d", i)
+		}
+	}
+
+	if _, err := root.Value("größe™"); err != nil {
+		t.Errorf("UTF-16 value name: %v", err)
+	}
+
+	if _, err := root.Value("Missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("reading a missing value returned %v", err)
+	}
+}
+
+func TestCorrupt(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(data []byte) []byte
+	}{
+		{"subkey list signature", func(data []byte) []byte {
+			copy(data[bytes.Index(data, []byte("ri\x02\x00")):], "xx")
+			return data
+		}},
+		{"subkey list count", func(data []byte) []byte {
+			binary.LittleEndian.PutUint16(data[bytes.Index(data, []byte("lf\x02\x00"))+2:], 0xffff)
+			return data
+		}},
+		{"big data segment count", func(data []byte) []byte {
+			binary.LittleEndian.PutUint16(data[bytes.Index(data, []byte("db\x02\x00"))+2:], 0xffff)
+			return data
+		}},
+		{"key name length", func(data []byte) []byte {
+			nk := bytes.Index(data, []byte("nk\x20\x00"))
+			binary.LittleEndian.PutUint16(data[nk+0x48:], 0xffff)
+			return data
+		}},
+		{"truncated", func(data []byte) []byte {
+			return data[:len(data)-8192]
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := walk(test.corrupt(readTestHive(t))); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+func TestNotHive(t *testing.T) {
+	data := readTestHive(t)
+
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"short":     data[:4095],
+		"signature": append([]byte("fger"), data[4:]...),
+	} {
+		if _, err := Parse(data); !errors.Is(err, ErrNotHive) {
+			t.Errorf("%s: Parse returned %v", name, err)
+		}
+	}
+
+	root := bytes.Clone(data)
+	binary.LittleEndian.PutUint32(root[0x24:], 0xfffffff0)
+	hive, err := Parse(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hive.Root(); err == nil {
+		t.Error("root outside the hive wasn't an error")
+	}
+}
+
+// Corrupt hives have to come back as errors, never a panic.
+func TestCorruptDoesntPanic(t *testing.T) {
+	data := readTestHive(t)
+
+	for size := baseBlockSize; size < len(data); size += 61 {
+		walk(data[:size])
+	}
+
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		corrupt := bytes.Clone(data)
+		for j := 0; j < 8; j++ {
+			corrupt[baseBlockSize+random.Intn(len(corrupt)-baseBlockSize)] = byte(random.Intn(256))
+		}
+		walk(corrupt)
+	}
+}
+
+// walk reads every key and value of a hive, returning the first error.
+func walk(data []byte) error {
+	hive, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	root, err := hive.Root()
+	if err != nil {
+		return err
+	}
+
+	return walkKey(root, 0)
+}
+
+func walkKey(key *Key, depth int) error {
+	if depth > 16 {
+		return nil
+	}
+
+	var first error
+	if _, err := key.Values(); err != nil {
+		first = err
+	}
+
+	keys, err := key.SubKeys()
+	if err != nil {
+		return err
+	}
+	for _, subKey := range keys {
+		if err := walkKey(subKey, depth+1); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+func decodeUTF16(data []byte) string {
+	chars := make([]uint16, len(data)/2)
+	for i := range chars {
+		chars[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+
+	return string(utf16.Decode(chars))
+}
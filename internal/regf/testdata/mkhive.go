@@ -0,0 +1,189 @@
+//go:build ignore
+
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * mkhive.go
+ * ---
+ * Last Modified: 20/10/2026 12:10PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+// Writes test.hiv, the hive the regf tests read, run from internal/regf:
+//
+//	go run testdata/mkhive.go
+//
+// ROOT
+//
+//	values Inline (REG_DWORD stored in the value), Short (REG_SZ), Big (REG_BINARY behind a db record), Größe (UTF-16 name)
+//	subkeys through an ri of an lf and an lh:
+//		Alpha, with Delta below it through an li
+//		Beta
+//		Γάμμα (UTF-16 name)
+package main
+
+import (
+	"encoding/binary"
+	"os"
+	"unicode/utf16"
+)
+
+const bigSize = 20000
+
+type hive struct {
+	bin []byte
+}
+
+// alloc adds an allocated cell and returns its offset from the first hive bin.
+func (h *hive) alloc(data []byte) uint32 {
+	offset := uint32(len(h.bin))
+	size := (4 + len(data) + 7) &^ 7
+
+	cell := make([]byte, size)
+	binary.LittleEndian.PutUint32(cell, uint32(-int32(size)))
+	copy(cell[4:], data)
+	h.bin = append(h.bin, cell...)
+
+	return offset
+}
+
+func le16(b []byte, v uint16) { binary.LittleEndian.PutUint16(b, v) }
+func le32(b []byte, v uint32) { binary.LittleEndian.PutUint32(b, v) }
+
+// name encodes a name compressed when it's Latin-1, UTF-16LE otherwise.
+func name(s string) ([]byte, bool) {
+	latin := []byte{}
+	for _, r := range s {
+		if r > 0xff {
+			var utf []byte
+			for _, c := range utf16.Encode([]rune(s)) {
+				utf = binary.LittleEndian.AppendUint16(utf, c)
+			}
+			return utf, false
+		}
+		latin = append(latin, byte(r))
+	}
+
+	return latin, true
+}
+
+func (h *hive) key(keyName string, subKeyCount uint32, subKeyList uint32, values []uint32) uint32 {
+	encoded, compressed := name(keyName)
+	nk := make([]byte, 0x4c+len(encoded))
+	copy(nk, "nk")
+	if compressed {
+		le16(nk[0x02:], 0x0020)
+	}
+	le32(nk[0x14:], subKeyCount)
+	le32(nk[0x1c:], subKeyList)
+	if len(values) > 0 {
+		list := make([]byte, 4*len(values))
+		for i, v := range values {
+			le32(list[i*4:], v)
+		}
+		le32(nk[0x24:], uint32(len(values)))
+		le32(nk[0x28:], h.alloc(list))
+	}
+	le16(nk[0x48:], uint16(len(encoded)))
+	copy(nk[0x4c:], encoded)
+
+	return h.alloc(nk)
+}
+
+func (h *hive) value(valueName string, valueType uint32, size uint32, dataOffset uint32) uint32 {
+	encoded, compressed := name(valueName)
+	vk := make([]byte, 0x14+len(encoded))
+	copy(vk, "vk")
+	le16(vk[0x02:], uint16(len(encoded)))
+	le32(vk[0x04:], size)
+	le32(vk[0x08:], dataOffset)
+	le32(vk[0x0c:], valueType)
+	if compressed {
+		le16(vk[0x10:], 0x0001)
+	}
+	copy(vk[0x14:], encoded)
+
+	return h.alloc(vk)
+}
+
+func (h *hive) list(signature string, offsets []uint32, hints bool) uint32 {
+	stride := 4
+	if hints {
+		stride = 8
+	}
+
+	list := make([]byte, 4+stride*len(offsets))
+	copy(list, signature)
+	le16(list[2:], uint16(len(offsets)))
+	for i, offset := range offsets {
+		le32(list[4+i*stride:], offset)
+	}
+
+	return h.alloc(list)
+}
+
+func utf16z(s string) []byte {
+	var data []byte
+	for _, c := range utf16.Encode([]rune(s + "\x00")) {
+		data = binary.LittleEndian.AppendUint16(data, c)
+	}
+	return data
+}
+
+func main() {
+	h := &hive{bin: make([]byte, 0x20)}
+	copy(h.bin, "hbin")
+
+	// REG_DWORD 0x12345678 stored in the data offset itself.
+	inline := h.value("Inline", 4, 4|0x80000000, 0x12345678)
+
+	short := utf16z("hello")
+	shortValue := h.value("Short", 1, uint32(len(short)), h.alloc(short))
+
+	big := make([]byte, bigSize)
+	for i := range big {
+		big[i] = byte(i % 251)
+	}
+	first := h.alloc(big[:16344])
+	second := h.alloc(big[16344:])
+	segments := make([]byte, 8)
+	le32(segments, first)
+	le32(segments[4:], second)
+	db := make([]byte, 8)
+	copy(db, "db")
+	le16(db[2:], 2)
+	le32(db[4:], h.alloc(segments))
+	bigValue := h.value("Big", 3, bigSize, h.alloc(db))
+
+	unicode := utf16z("wert")
+	unicodeValue := h.value("Größe™", 1, uint32(len(unicode)), h.alloc(unicode))
+
+	delta := h.key("Delta", 0, 0, nil)
+	alpha := h.key("Alpha", 1, h.list("li", []uint32{delta}, false), nil)
+	beta := h.key("Beta", 0, 0, nil)
+	gamma := h.key("Γάμμα", 0, 0, nil)
+
+	lf := h.list("lf", []uint32{alpha, beta}, true)
+	lh := h.list("lh", []uint32{gamma}, true)
+	ri := h.list("ri", []uint32{lf, lh}, false)
+	root := h.key("ROOT", 3, ri, []uint32{inline, shortValue, bigValue, unicodeValue})
+
+	// Bins are a multiple of 4K, the rest is one free cell.
+	if pad := (4096 - len(h.bin)%4096) % 4096; pad > 0 {
+		free := make([]byte, pad)
+		le32(free, uint32(pad))
+		h.bin = append(h.bin, free...)
+	}
+	le32(h.bin[0x08:], uint32(len(h.bin)))
+
+	base := make([]byte, 4096)
+	copy(base, "regf")
+	le32(base[0x24:], root)
+	le32(base[0x28:], uint32(len(h.bin)))
+
+	if err := os.WriteFile("testdata/test.hiv", append(base, h.bin...), 0o644); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * bundle.go
+ * ---
+ * Last Modified: 20/10/2026 02:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package signature
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// The key signature bundles are signed with, the private half never leaves
+// the release machine. It's empty until the release owners pin theirs, every
+// bundle is rejected until then.
+//
+//go:embed release.pub
+var releaseKey string
+
+// PublicKey is the pinned key every bundle has to be signed with, nil if none is pinned.
+var PublicKey = mustDecodeKey(releaseKey)
+
+// ErrNoReleaseKey is returned for every bundle while no release key is pinned.
+var ErrNoReleaseKey = errors.New("no release key configured")
+
+// Bundle is a signature file signed for distribution outside of a release.
+//
+// The signature covers the payload bytes as they are, so the file doesn't
+// need to be re-encoded the same way to verify it.
+type Bundle struct {
+	Payload   []byte `json:"payload"`   // A signature file, see File.
+	Signature []byte `json:"signature"` // ed25519 signature of Payload.
+}
+
+// Open verifies a bundle against key and parses the signature file inside it.
+func Open(bundle []byte, key ed25519.PublicKey) (*File, error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, ErrNoReleaseKey
+	}
+
+	var b Bundle
+	if err := json.Unmarshal(bundle, &b); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+
+	if len(b.Signature) != ed25519.SignatureSize || !ed25519.Verify(key, b.Payload, b.Signature) {
+		return nil, errors.New("bundle signature doesn't match the pinned key")
+	}
+
+	return Parse(b.Payload)
+}
+
+// Seal signs a signature file into a bundle, the file is validated first.
+func Seal(file []byte, key ed25519.PrivateKey) ([]byte, error) {
+	if _, err := Parse(file); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(Bundle{Payload: file, Signature: ed25519.Sign(key, file)})
+}
+
+func mustDecodeKey(encoded string) ed25519.PublicKey {
+	encoded = string(bytes.TrimSpace([]byte(encoded)))
+	if encoded == "" {
+		return nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		panic("release.pub is not a base64 encoded ed25519 public key")
+	}
+
+	return key
+}
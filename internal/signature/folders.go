@@ -0,0 +1,41 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * folders.go
+ * ---
+ * Last Modified: 19/10/2026 10:20PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package signature
+
+import (
+	"strings"
+)
+
+// KnownFolders are the folders Windows file signatures start with, e.g.
+// %SysNative%\drivers\vmmouse.sys. They're resolved at runtime since Windows
+// doesn't have to live at C:\Windows.
+//
+// SysNative is the real System32, even from a 32-bit process that WOW64
+// would redirect to SysWOW64.
+var KnownFolders = []string{"SystemRoot", "System32", "SysNative", "ProgramFiles", "ProgramFiles(x86)"}
+
+// SplitKnownFolder splits a Windows file signature into its known folder and
+// the path below it. The folder is matched ignoring case.
+func SplitKnownFolder(file string) (string, string, bool) {
+	token, rest, ok := strings.Cut(strings.TrimPrefix(file, "%"), "%")
+	if !ok || !strings.HasPrefix(file, "%") {
+		return "", "", false
+	}
+
+	for _, folder := range KnownFolders {
+		if strings.EqualFold(token, folder) {
+			return folder, strings.TrimPrefix(rest, `\`), true
+		}
+	}
+
+	return "", "", false
+}
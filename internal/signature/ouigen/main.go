@@ -0,0 +1,126 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * main.go
+ * ---
+ * Last Modified: 19/10/2026 12:10PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+// Command ouigen rebuilds the oui lists in signatures.json from the
+// embedded snapshot of the IEEE MA-L registry.
+//
+// oui.txt only holds the registry entries we care about, to refresh it
+// replace it with https://standards-oui.ieee.org/oui/oui.txt and run
+// go generate ./internal/signature.
+package main
+
+import (
+	"bufio"
+	_ "embed"
+	"flag"
+	"github.com/Inspect-Element-Ltd/vm/internal/signature"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+//go:embed oui.txt
+var registry string
+
+var (
+	// Organisations that only hand out addresses to virtual NICs.
+	organisations = map[string]string{
+		"freebsd foundation":     "bhyve",
+		"parallels, inc.":        "Parallels",
+		"pcs systemtechnik gmbh": "VirtualBox",
+		"qumranet inc.":          "Red Hat",
+		"vmware, inc.":           "VMware",
+		"xensource, inc.":        "Xen",
+	}
+
+	// Organisations that also make physical hardware, only these prefixes are virtual.
+	prefixes = map[string]string{
+		"00-03-FF": "VirtualPC",
+		"00-15-5D": "Hyper-V",
+	}
+
+	// Locally administered prefixes hypervisors and clouds hand out, these are never in the registry.
+	local = map[string]string{
+		"0A-00-27": "VirtualBox", // Host-only adapters
+		"12-31-39": "Amazon",     // EC2 Xen instances
+		"42-01":    "Google",     // Compute Engine, followed by the internal IP
+		"52-54-00": "QEMU",
+	}
+)
+
+func main() {
+	out := flag.String("o", "signatures.json", "signature file to update")
+	flag.Parse()
+
+	data, err := os.ReadFile(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := signature.Parse(data)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ouis := make(map[string][]string)
+
+	scanner := bufio.NewScanner(strings.NewReader(registry))
+	for scanner.Scan() {
+		// 00-50-56   (hex)		VMware, Inc.
+		hex, organisation, found := strings.Cut(scanner.Text(), "(hex)")
+		if !found {
+			continue
+		}
+
+		hex = strings.TrimSpace(hex)
+		organisation = strings.TrimSpace(organisation)
+
+		vendor, ok := prefixes[hex]
+		if !ok {
+			vendor, ok = organisations[strings.ToLower(organisation)]
+		}
+
+		if ok {
+			ouis[vendor] = append(ouis[vendor], strings.ReplaceAll(hex, "-", ":"))
+		}
+	}
+
+	for hex, vendor := range local {
+		ouis[vendor] = append(ouis[vendor], strings.ReplaceAll(hex, "-", ":"))
+	}
+
+	for name, vendor := range file.Vendors {
+		vendor.OUI = nil
+		file.Vendors[name] = vendor
+	}
+
+	for name, list := range ouis {
+		sort.Strings(list)
+
+		vendor := file.Vendors[name]
+		vendor.OUI = list
+		file.Vendors[name] = vendor
+	}
+
+	if err := file.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := file.Marshal()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
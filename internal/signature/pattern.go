@@ -0,0 +1,118 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * pattern.go
+ * ---
+ * Last Modified: 20/10/2026 03:05PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package signature
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// GUID is the placeholder matching a braced GUID, e.g. a key under Control\Video.
+const GUID = "{GUID}"
+
+const guidExpr = `\{[0-9A-F]{8}-[0-9A-F]{4}-[0-9A-F]{4}-[0-9A-F]{4}-[0-9A-F]{12}\}`
+
+// CompilePattern turns a signature pattern into the regular expression it's matched with.
+//
+// '*' matches any run of characters, '?' a single one and {GUID} a braced
+// GUID, everything else is literal. Matching is case-insensitive and covers
+// the whole string.
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("(?is)^")
+
+	for rest := pattern; rest != ""; {
+		if after, found := strings.CutPrefix(rest, GUID); found {
+			expr.WriteString(guidExpr)
+			rest = after
+			continue
+		}
+
+		// Whole runes, so non-ASCII names like a localized device stay intact.
+		r, size := utf8.DecodeRuneInString(rest)
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(rest[:size]))
+		}
+		rest = rest[size:]
+	}
+
+	expr.WriteString("$")
+
+	return regexp.Compile(expr.String())
+}
+
+// IsPattern reports whether value contains any wildcards or placeholders.
+func IsPattern(value string) bool {
+	return strings.ContainsAny(value, "*?") || strings.Contains(value, GUID)
+}
+
+// Comparison is a numeric registry value signature, e.g. ">=0x2" or "!=0".
+type Comparison struct {
+	Op    string
+	Value uint64
+}
+
+// Longest first so "<=" isn't read as "<".
+var comparisonOps = []string{"==", "!=", "<=", ">=", "=", "<", ">"}
+
+// ParseComparison parses a numeric signature, the value may be decimal or hex.
+//
+// Values that don't start with an operator aren't comparisons and return nil.
+func ParseComparison(value string) (*Comparison, error) {
+	for _, op := range comparisonOps {
+		operand, found := strings.CutPrefix(value, op)
+		if !found {
+			continue
+		}
+
+		n, err := strconv.ParseUint(strings.TrimSpace(operand), 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid comparison %q", value)
+		}
+
+		if op == "==" {
+			op = "="
+		}
+
+		return &Comparison{Op: op, Value: n}, nil
+	}
+
+	return nil, nil
+}
+
+// Matches reports whether n satisfies the comparison.
+func (c *Comparison) Matches(n uint64) bool {
+	switch c.Op {
+	case "=":
+		return n == c.Value
+	case "!=":
+		return n != c.Value
+	case "<":
+		return n < c.Value
+	case "<=":
+		return n <= c.Value
+	case ">":
+		return n > c.Value
+	case ">=":
+		return n >= c.Value
+	}
+
+	return false
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * pattern_test.go
+ * ---
+ * Last Modified: 20/10/2026 03:05PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package signature
+
+import "testing"
+
+func TestCompilePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		match   bool
+	}{
+		{pattern: "VBOX*", value: "vbox   - 1", match: true},
+		{pattern: "VBOX*", value: "Oracle VBOX", match: false},
+		{pattern: "Scsi Port ?", value: "Scsi Port 2", match: true},
+		{pattern: "Scsi Port ?", value: "Scsi Port 12", match: false},
+		{pattern: "a.b", value: "axb", match: false},
+		{pattern: `Video\{GUID}\0000`, value: `Video\{2D3B4F6A-1B2C-4D5E-8F90-A1B2C3D4E5F6}\0000`, match: true},
+		{pattern: `Video\{GUID}\0000`, value: `Video\{not-a-guid}\0000`, match: false},
+		// Non-ASCII literals are matched as whole characters, ignoring case.
+		{pattern: "Contrôleur vidéo*", value: "Contrôleur vidéo de base Microsoft", match: true},
+		{pattern: "GRÖSSE", value: "größe", match: false},
+		{pattern: "Größe", value: "GRÖßE", match: true},
+		{pattern: "Gr?ße", value: "Größe", match: true},
+		{pattern: "虚拟*", value: "虚拟机", match: true},
+	}
+
+	for _, test := range tests {
+		expr, err := CompilePattern(test.pattern)
+		if err != nil {
+			t.Fatalf("%q: %v", test.pattern, err)
+		}
+
+		if match := expr.MatchString(test.value); match != test.match {
+			t.Errorf("%q matching %q is %v, expected %v", test.pattern, test.value, match, test.match)
+		}
+	}
+}
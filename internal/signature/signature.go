@@ -0,0 +1,379 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * signature.go
+ * ---
+ * Last Modified: 20/10/2026 03:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+// Package signature holds the declarative signature file every check is built from.
+//
+// Adding a vendor string is a change to signatures.json, the checks compile
+// it into their lookup tables when the program starts.
+package signature
+
+//go:generate go run ./ouigen -o signatures.json
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is the version of the file format this package understands.
+const Schema = 1
+
+//go:embed signatures.json
+var Embedded []byte
+
+// File is the root of a signature file.
+type File struct {
+	Schema              int                 `json:"schema"`
+	Version             int                 `json:"version"` // Increases with every release of the signatures, e.g. 2026101901.
+	Vendors             map[string]Vendor   `json:"vendors"`
+	NATDefaults         []NATDefault        `json:"nat_defaults"`
+	Passthrough         Passthrough         `json:"passthrough"`
+	CompatibilityLayers CompatibilityLayers `json:"compatibility_layers"`
+	SMBIOSPlaceholders  []string            `json:"smbios_placeholders"`
+}
+
+// Vendor is every signature belonging to a single vendor.
+type Vendor struct {
+	RegistryKeys            []string            `json:"registry_keys,omitempty"`
+	RegistryValues          map[string][]string `json:"registry_values,omitempty"`
+	Files                   []string            `json:"files,omitempty"`
+	Services                []string            `json:"services,omitempty"`       // Windows service and driver names, may contain wildcards.
+	HardwareIDs             []string            `json:"hardware_ids,omitempty"`   // Windows hardware IDs of USB, ACPI and storage devices, may contain wildcards. PCI devices go by PCIVendors.
+	DeviceObjects           []string            `json:"device_objects,omitempty"` // Windows device and pipe names, e.g. \\.\VBoxGuest or \\.\pipe\VBoxTrayIPC.
+	OUI                     []string            `json:"oui,omitempty"`
+	SMBIOS                  []string            `json:"smbios,omitempty"`
+	ACPIOEMIDs              []string            `json:"acpi_oem_ids,omitempty"` // Matched against the start of the OEM, OEM table and creator IDs of ACPI tables.
+	PCIVendors              []string            `json:"pci_vendors,omitempty"`
+	NICDrivers              []string            `json:"nic_drivers,omitempty"`
+	NICBuses                []string            `json:"nic_buses,omitempty"`
+	HostAdapterNames        []string            `json:"host_adapter_names,omitempty"`
+	HostAdapterDescriptions []string            `json:"host_adapter_descriptions,omitempty"`
+	IORegistryVendors       []string            `json:"ioregistry_vendors,omitempty"`
+}
+
+// Lists returns every list of strings in the vendor keyed by its JSON name.
+func (v Vendor) Lists() map[string][]string {
+	return map[string][]string{
+		"registry_keys":             v.RegistryKeys,
+		"files":                     v.Files,
+		"services":                  v.Services,
+		"hardware_ids":              v.HardwareIDs,
+		"device_objects":            v.DeviceObjects,
+		"oui":                       v.OUI,
+		"smbios":                    v.SMBIOS,
+		"acpi_oem_ids":              v.ACPIOEMIDs,
+		"pci_vendors":               v.PCIVendors,
+		"nic_drivers":               v.NICDrivers,
+		"nic_buses":                 v.NICBuses,
+		"host_adapter_names":        v.HostAdapterNames,
+		"host_adapter_descriptions": v.HostAdapterDescriptions,
+		"ioregistry_vendors":        v.IORegistryVendors,
+	}
+}
+
+// NATDefault describes the network a hypervisor hands its guests by default,
+// every field that is set has to match.
+type NATDefault struct {
+	Vendor        string `json:"vendor"`
+	Name          string `json:"name"`
+	Network       string `json:"network,omitempty"`
+	Gateway       string `json:"gateway,omitempty"`
+	GatewayHost   byte   `json:"gateway_host,omitempty"`
+	GatewayVendor string `json:"gateway_vendor,omitempty"`
+}
+
+// Passthrough is the devices GPU passthrough VMs are built with.
+type Passthrough struct {
+	IVSHMEM      string            `json:"ivshmem"`
+	Displays     map[string]string `json:"displays"`
+	GPUVendors   map[string]string `json:"gpu_vendors"`
+	RegistryKeys []string          `json:"registry_keys"`
+}
+
+// CompatibilityLayers is what Wine and the layers built on it, e.g. Proton, leave behind.
+type CompatibilityLayers struct {
+	RegistryKeys []string `json:"registry_keys"`
+}
+
+var (
+	pciVendorID = regexp.MustCompile(`^[0-9a-f]{4}$`)
+	pciID       = regexp.MustCompile(`^[0-9a-f]{4}:[0-9a-f]{4}$`)
+	hives       = []string{`HKLM\`, `HKCU\`, `HKCR\`, `HKU\`, `HKCC\`}
+)
+
+// Parse decodes and validates a signature file.
+func Parse(data []byte) (*File, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var f File
+	if err := decoder.Decode(&f); err != nil {
+		return nil, fmt.Errorf("invalid signature file: %w", err)
+	}
+
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// Validate checks the file against the schema, every problem found is returned.
+func (f *File) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if f.Schema != Schema {
+		fail("schema %d is not supported, expected %d", f.Schema, Schema)
+	}
+	if f.Version <= 0 {
+		fail("version must be positive")
+	}
+
+	for _, name := range vendorNames(f.Vendors) {
+		vendor := f.Vendors[name]
+		if strings.TrimSpace(name) == "" {
+			fail("vendor name is empty")
+		}
+
+		for field, list := range vendor.Lists() {
+			for _, dup := range duplicates(list) {
+				fail("%s: %s lists %q more than once", name, field, dup)
+			}
+			for _, value := range list {
+				if strings.TrimSpace(value) == "" {
+					fail("%s: %s has an empty entry", name, field)
+				}
+			}
+		}
+
+		for _, key := range vendor.RegistryKeys {
+			if !hasHive(key) {
+				fail("%s: registry key %q doesn't start with a known hive", name, key)
+			}
+			if _, err := CompilePattern(key); err != nil {
+				fail("%s: registry key %q: %v", name, key, err)
+			}
+		}
+		for key, values := range vendor.RegistryValues {
+			if !hasHive(key) {
+				fail("%s: registry value %q doesn't start with a known hive", name, key)
+			}
+			if len(values) == 0 {
+				fail("%s: registry value %q has nothing to match", name, key)
+			}
+			for _, dup := range duplicates(values) {
+				fail("%s: registry value %q lists %q more than once", name, key, dup)
+			}
+			for _, value := range append([]string{key}, values...) {
+				if _, err := CompilePattern(value); err != nil {
+					fail("%s: registry value %q: %v", name, value, err)
+				}
+			}
+			for _, value := range values {
+				if _, err := ParseComparison(value); err != nil {
+					fail("%s: registry value %q: %v", name, key, err)
+				}
+			}
+		}
+		for _, file := range vendor.Files {
+			if _, _, ok := SplitKnownFolder(file); !ok && !strings.HasPrefix(file, "/") {
+				fail("%s: file %q must start with a known folder like %%SysNative%% or be an absolute macOS or Linux path", name, file)
+			}
+		}
+		for _, service := range vendor.Services {
+			if _, err := CompilePattern(service); err != nil {
+				fail("%s: service %q: %v", name, service, err)
+			}
+		}
+		for _, id := range vendor.HardwareIDs {
+			if bus, _, ok := strings.Cut(id, `\`); !ok || bus == "" || IsPattern(bus) {
+				fail("%s: hardware ID %q must start with its bus, e.g. USB\\", name, id)
+			}
+			if _, err := CompilePattern(id); err != nil {
+				fail("%s: hardware ID %q: %v", name, id, err)
+			}
+		}
+		for _, object := range vendor.DeviceObjects {
+			if rest := strings.TrimPrefix(object, `\\.\`); rest == object || rest == "" || IsPattern(rest) {
+				fail(`%s: device object %q must be a \\.\ path without wildcards`, name, object)
+			}
+		}
+		for _, id := range vendor.ACPIOEMIDs {
+			if id == "" || len(id) > 8 {
+				fail("%s: ACPI OEM ID %q must be 1 to 8 characters", name, id)
+			}
+		}
+		for _, oui := range vendor.OUI {
+			if _, err := ParseOUI(oui); err != nil {
+				fail("%s: %v", name, err)
+			}
+		}
+		for _, id := range vendor.PCIVendors {
+			if !pciVendorID.MatchString(id) {
+				fail("%s: PCI vendor %q must be 4 lowercase hex digits", name, id)
+			}
+		}
+	}
+
+	// These are looked up by value, so two vendors can't share one.
+	owners := map[string]map[string]string{"oui": {}, "pci_vendors": {}, "nic_buses": {}, "services": {}, "hardware_ids": {}, "device_objects": {}, "acpi_oem_ids": {}}
+	for _, name := range vendorNames(f.Vendors) {
+		vendor := f.Vendors[name]
+		for field, list := range vendor.Lists() {
+			if owners[field] == nil {
+				continue
+			}
+
+			for _, value := range list {
+				key := strings.ToLower(value)
+				if owner, ok := owners[field][key]; ok {
+					fail("%s: %s %q is already claimed by %s", name, field, value, owner)
+				}
+				owners[field][key] = name
+			}
+		}
+	}
+
+	for _, nat := range f.NATDefaults {
+		if _, ok := f.Vendors[nat.Vendor]; !ok {
+			fail("NAT default %q belongs to unknown vendor %q", nat.Name, nat.Vendor)
+		}
+		if nat.Network != "" {
+			if _, _, err := net.ParseCIDR(nat.Network); err != nil {
+				fail("NAT default %q: %v", nat.Name, err)
+			}
+		}
+		if nat.Gateway != "" && net.ParseIP(nat.Gateway) == nil {
+			fail("NAT default %q: invalid gateway %q", nat.Name, nat.Gateway)
+		}
+		if nat.GatewayVendor != "" {
+			if _, ok := f.Vendors[nat.GatewayVendor]; !ok {
+				fail("NAT default %q: unknown gateway vendor %q", nat.Name, nat.GatewayVendor)
+			}
+		}
+		if nat.Network == "" && nat.Gateway == "" && nat.GatewayHost == 0 && nat.GatewayVendor == "" {
+			fail("NAT default %q matches every network", nat.Name)
+		}
+	}
+
+	if !pciID.MatchString(f.Passthrough.IVSHMEM) {
+		fail("passthrough: IVSHMEM device %q must be vendor:device", f.Passthrough.IVSHMEM)
+	}
+	for id := range f.Passthrough.Displays {
+		if !pciID.MatchString(id) {
+			fail("passthrough: display %q must be vendor:device", id)
+		}
+	}
+	for id := range f.Passthrough.GPUVendors {
+		if !pciVendorID.MatchString(id) {
+			fail("passthrough: GPU vendor %q must be 4 lowercase hex digits", id)
+		}
+	}
+	for _, key := range f.Passthrough.RegistryKeys {
+		if !hasHive(key) {
+			fail("passthrough: registry key %q doesn't start with a known hive", key)
+		}
+	}
+	for _, key := range f.CompatibilityLayers.RegistryKeys {
+		if !hasHive(key) {
+			fail("compatibility layers: registry key %q doesn't start with a known hive", key)
+		}
+		if _, err := CompilePattern(key); err != nil {
+			fail("compatibility layers: registry key %q: %v", key, err)
+		}
+	}
+	for _, dup := range duplicates(f.CompatibilityLayers.RegistryKeys) {
+		fail("compatibility layers: registry_keys lists %q more than once", dup)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Marshal encodes the file the way signatures.json is laid out.
+func (f *File) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(f); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ParseOUI parses a colon separated MAC address prefix like 00:50:56.
+func ParseOUI(oui string) ([]byte, error) {
+	parts := strings.Split(oui, ":")
+	if len(parts) == 0 || len(parts) > 6 {
+		return nil, fmt.Errorf("invalid OUI %q", oui)
+	}
+
+	prefix := make([]byte, 0, len(parts))
+	for _, part := range parts {
+		if len(part) != 2 {
+			return nil, fmt.Errorf("invalid OUI %q", oui)
+		}
+		b, err := strconv.ParseUint(part, 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OUI %q", oui)
+		}
+		prefix = append(prefix, byte(b))
+	}
+
+	return prefix, nil
+}
+
+// vendorNames returns the vendor names sorted, so errors come out in the same order every time.
+func vendorNames(vendors map[string]Vendor) []string {
+	names := make([]string, 0, len(vendors))
+	for name := range vendors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func hasHive(key string) bool {
+	for _, hive := range hives {
+		if strings.HasPrefix(strings.ToUpper(key), hive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func duplicates(list []string) []string {
+	seen := make(map[string]bool, len(list))
+
+	var dups []string
+	for _, value := range list {
+		if seen[value] {
+			dups = append(dups, value)
+		}
+		seen[value] = true
+	}
+
+	return dups
+}
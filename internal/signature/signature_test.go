@@ -0,0 +1,163 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * signature_test.go
+ * ---
+ * Last Modified: 20/10/2026 03:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package signature
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestEmbedded(t *testing.T) {
+	f, err := Parse(Embedded)
+	if err != nil {
+		t.Fatalf("embedded signatures are invalid: %v", err)
+	}
+
+	if f.Schema != Schema {
+		t.Errorf("schema is %d, expected %d", f.Schema, Schema)
+	}
+
+	// YYYYMMDDNN, the release date and a counter.
+	if version := strconv.Itoa(f.Version); len(version) != 10 || f.Version < 2024010100 {
+		t.Errorf("version %d isn't a release version", f.Version)
+	}
+
+	if len(f.Vendors) == 0 {
+		t.Error("no vendors")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	const document = `{"schema": 1, "version": 1, "vendors": %s, "nat_defaults": %s, "passthrough": {"ivshmem": "1af4:1110"}, "compatibility_layers": %s, "smbios_placeholders": []}`
+
+	tests := []struct {
+		name    string
+		vendors string
+		nat     string
+		layers  string
+		err     string // Empty when the document is valid.
+	}{
+		{
+			name:    "valid",
+			vendors: `{"VMware": {"registry_keys": ["HKLM\\SOFTWARE\\VMware, Inc."], "oui": ["00:50:56"]}}`,
+		},
+		{
+			name:    "duplicate entry",
+			vendors: `{"VMware": {"services": ["vmtools", "vmtools"]}}`,
+			err:     `VMware: services lists "vmtools" more than once`,
+		},
+		{
+			name:    "duplicate registry value",
+			vendors: `{"VMware": {"registry_values": {"HKLM\\HARDWARE\\Description\\System\\SystemBiosVersion": ["VMWARE", "VMWARE"]}}}`,
+			err:     `lists "VMWARE" more than once`,
+		},
+		{
+			name:    "empty entry",
+			vendors: `{"VMware": {"smbios": [" "]}}`,
+			err:     "VMware: smbios has an empty entry",
+		},
+		{
+			name:    "unknown hive",
+			vendors: `{"VMware": {"registry_keys": ["HKEY_NOWHERE\\SOFTWARE\\VMware, Inc."]}}`,
+			err:     "doesn't start with a known hive",
+		},
+		{
+			name:    "unknown hive of a value",
+			vendors: `{"VMware": {"registry_values": {"SOFTWARE\\VMware, Inc.\\InstallPath": ["*"]}}}`,
+			err:     "doesn't start with a known hive",
+		},
+		{
+			name:    "bad comparison",
+			vendors: `{"VMware": {"registry_values": {"HKLM\\SOFTWARE\\VMware, Inc.\\Level": [">=two"]}}}`,
+			err:     `invalid comparison ">=two"`,
+		},
+		{
+			name:    "hardware ID without a bus",
+			vendors: `{"VirtualBox": {"hardware_ids": ["*VID_80EE*"]}}`,
+			err:     "must start with its bus",
+		},
+		{
+			name:    "device object with a wildcard",
+			vendors: `{"VirtualBox": {"device_objects": ["\\\\.\\VBox*"]}}`,
+			err:     "without wildcards",
+		},
+		{
+			name:    "bad OUI",
+			vendors: `{"VMware": {"oui": ["00:50:5G"]}}`,
+			err:     `invalid OUI "00:50:5G"`,
+		},
+		{
+			name:    "OUI owned by two vendors",
+			vendors: `{"VMware": {"oui": ["00:50:56"]}, "Fake": {"oui": ["00:50:56"]}}`,
+			err:     `VMware: oui "00:50:56" is already claimed by Fake`,
+		},
+		{
+			name:    "PCI vendor owned by two vendors ignoring case",
+			vendors: `{"VMware": {"pci_vendors": ["15ad"]}, "Fake": {"pci_vendors": ["15AD"]}}`,
+			err:     "is already claimed by Fake",
+		},
+		{
+			name:    "NAT default of an unknown vendor",
+			vendors: `{"VMware": {}}`,
+			nat:     `[{"vendor": "Fake", "name": "NAT", "network": "192.168.0.0/16"}]`,
+			err:     `NAT default "NAT" belongs to unknown vendor "Fake"`,
+		},
+		{
+			name:    "NAT default matching everything",
+			vendors: `{"VMware": {}}`,
+			nat:     `[{"vendor": "VMware", "name": "NAT"}]`,
+			err:     `NAT default "NAT" matches every network`,
+		},
+		{
+			name:    "compatibility layer key",
+			vendors: `{}`,
+			layers:  `{"registry_keys": ["HKCU\\SOFTWARE\\Wine"]}`,
+		},
+		{
+			name:    "compatibility layer key without a hive",
+			vendors: `{}`,
+			layers:  `{"registry_keys": ["SOFTWARE\\Wine"]}`,
+			err:     `compatibility layers: registry key "SOFTWARE\\Wine" doesn't start with a known hive`,
+		},
+		{
+			name:    "duplicate compatibility layer key",
+			vendors: `{}`,
+			layers:  `{"registry_keys": ["HKLM\\SOFTWARE\\Wine", "HKLM\\SOFTWARE\\Wine"]}`,
+			err:     "compatibility layers: registry_keys lists",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nat := test.nat
+			if nat == "" {
+				nat = "[]"
+			}
+			layers := test.layers
+			if layers == "" {
+				layers = "{}"
+			}
+
+			_, err := Parse([]byte(fmt.Sprintf(document, test.vendors, nat, layers)))
+			switch {
+			case test.err == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case test.err != "" && err == nil:
+				t.Errorf("expected an error containing %q", test.err)
+			case test.err != "" && !strings.Contains(err.Error(), test.err):
+				t.Errorf("error %q doesn't contain %q", err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * file.go
+ * ---
+ * Last Modified: 19/10/2026 09:35PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package util
+
+import (
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// ReadString reads a small text file, e.g. from sysfs or procfs,
+// and returns its contents without surrounding whitespace.
+func ReadString(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// ReadFSString is ReadString for a file in fsys.
+func ReadFSString(fsys fs.FS, name string) (string, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
@@ -5,7 +5,7 @@
  *
  * detect.go
  * ---
- * Last Modified: 11/05/2024 01:13AM (BST)
+ * Last Modified: 20/10/2026 03:20AM (BST)
  * Modified By: Gianluca Oliva <hello@gian.sh>
  */
 
@@ -13,9 +13,51 @@ package vmdetect
 
 import (
 	"github.com/Inspect-Element-Ltd/vm/internal/check"
-	"github.com/klauspost/cpuid/v2"
 )
 
+type (
+	// Report is everything found while scanning the system.
+	Report = check.Report
+	// Evidence is a single finding, see Class for what it means.
+	Evidence = check.Evidence
+	// Observation is what a single signal claims about the system.
+	Observation = check.Observation
+	// Class describes what a piece of Evidence says about the system.
+	Class = check.Class
+)
+
+const (
+	ClassVM                  = check.ClassVM
+	ClassCloaking            = check.ClassCloaking
+	ClassPassthrough         = check.ClassPassthrough
+	ClassHypervisorInstalled = check.ClassHypervisorInstalled
+	ClassCompatibilityLayer  = check.ClassCompatibilityLayer
+)
+
+// VendorWindowsSandbox is the vendor Windows Sandbox, Application Guard and
+// Hyper-V isolated containers are reported as, so they can be allowed
+// without allowing every Hyper-V VM.
+const VendorWindowsSandbox = check.VendorWindowsSandbox
+
+// Keys of Evidence.Metadata.
+const (
+	MetadataHostName     = check.MetadataHostName
+	MetadataVMName       = check.MetadataVMName
+	MetadataVMID         = check.MetadataVMID
+	MetadataToolsVersion = check.MetadataToolsVersion
+)
+
+// CollectGuestMetadata turns collecting what a guest knows about its VM on or
+// off, it's off by default. When on, registry evidence carries the host name,
+// VM name and ID Hyper-V shares with its guests and the version of VMware Tools
+// or VirtualBox Guest Additions in Evidence.Metadata.
+//
+// The host and VM names can identify people, only turn it on when you're
+// allowed to collect them.
+func CollectGuestMetadata(enabled bool) {
+	check.CollectGuestMetadata(enabled)
+}
+
 // IsVM attempts to figure out if the current system is a virtual machine.
 //
 // Calls Check but only returns a boolean value,
@@ -30,16 +72,21 @@ func IsVM() bool {
 // If a VM is detected the Vendor and why it was detected is also returned,
 // these values will be empty if the machine is not detected as being virtualised.
 func Check() (bool, string, string) {
-	switch cpuid.CPU.VendorID {
-	case cpuid.MSVM, cpuid.KVM, cpuid.VMware, cpuid.XenHVM, cpuid.Bhyve:
-		return true, cpuid.CPU.VendorString, "CPUID"
-	default:
-		break
-	}
-
-	if vm, vendor, why := check.MACAddress(); vm {
-		return vm, vendor, why
-	}
-
-	return detectVM()
+	return Scan().Verdict()
+}
+
+// Scan runs every check and returns all the evidence that was found,
+// including contradictions between signals that suggest a cloaked VM.
+func Scan() *Report {
+	r := &Report{}
+
+	check.CPUID(r)
+	check.MACAddress(r)
+	check.NetworkInterfaces(r)
+	check.NetworkEnvironment(r)
+	detectVM(r)
+
+	check.Consistency(r)
+
+	return r
 }
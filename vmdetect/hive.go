@@ -0,0 +1,48 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * hive.go
+ * ---
+ * Last Modified: 19/10/2026 08:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package vmdetect
+
+import (
+	"fmt"
+	"github.com/Inspect-Element-Ltd/vm/internal/check"
+	"github.com/Inspect-Element-Ltd/vm/internal/regf"
+)
+
+// ScanHives matches the Windows registry signatures against hive files, e.g.
+// SYSTEM, SOFTWARE and NTUSER.DAT pulled from a disk image. It runs on any OS.
+//
+// Each hive is mounted where Windows loads it going by its file name, so the
+// files need to keep their original names.
+func ScanHives(paths ...string) (*Report, error) {
+	hives := make(map[string]*regf.Hive)
+	for _, path := range paths {
+		mount, ok := check.HiveMount(path)
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown hive, expected SYSTEM, SOFTWARE, SAM, SECURITY, NTUSER.DAT or USRCLASS.DAT", path)
+		}
+
+		hive, err := regf.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		hives[mount] = hive
+	}
+
+	r := &Report{}
+	if err := check.OfflineRegistry(r, hives); err != nil {
+		return nil, err
+	}
+
+	check.Consistency(r)
+
+	return r, nil
+}
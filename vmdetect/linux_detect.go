@@ -5,7 +5,7 @@
  *
  * linux_detect.go
  * ---
- * Last Modified: 11/05/2024 01:15AM (BST)
+ * Last Modified: 20/10/2026 01:40PM (BST)
  * Modified By: Gianluca Oliva <hello@gian.sh>
  */
 
@@ -13,7 +13,11 @@
 
 package vmdetect
 
-func detectVM() (bool, string, string) {
-	// TODO: Linux
-	return false, "", ""
+import (
+	"github.com/Inspect-Element-Ltd/vm/internal/check"
+)
+
+func detectVM(r *check.Report) {
+	check.ACPI(r)
+	check.Passthrough(r)
 }
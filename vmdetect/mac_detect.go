@@ -5,7 +5,7 @@
  *
  * mac_detect.go
  * ---
- * Last Modified: 11/05/2024 01:15AM (BST)
+ * Last Modified: 20/10/2026 04:10AM (BST)
  * Modified By: Gianluca Oliva <hello@gian.sh>
  */
 
@@ -20,7 +20,7 @@ import (
 )
 
 func SIPDisabled() bool {
-	sip, err := util.InvokeCMD("bash", "-c", "csrutil status")
+	sip, err := util.InvokeCMD("csrutil", "status")
 	if err != nil {
 		return false
 	}
@@ -28,19 +28,9 @@ func SIPDisabled() bool {
 	return strings.TrimSpace(sip) != "System Integrity Protection status: enabled."
 }
 
-func detectVM() (bool, string, string) {
-
-	if vm, vendor, why := check.HardwareModel(); vm {
-		return vm, vendor, why
-	}
-
-	if vm, vendor, why := check.MemorySize(); vm {
-		return vm, vendor, why
-	}
-
-	if vm, vendor, why := check.Registry(); vm {
-		return vm, vendor, why
-	}
-
-	return false, "", ""
+func detectVM(r *check.Report) {
+	check.HardwareModel(r)
+	check.MemorySize(r)
+	check.Registry(r)
+	check.FileSystem(r)
 }
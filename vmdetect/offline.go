@@ -0,0 +1,42 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * offline.go
+ * ---
+ * Last Modified: 19/10/2026 09:35PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package vmdetect
+
+import (
+	"github.com/Inspect-Element-Ltd/vm/internal/check"
+	"io/fs"
+	"os"
+)
+
+// ScanRoot runs the file based checks against the root of another system,
+// e.g. a mounted Windows partition or an extracted image. It runs on any OS.
+//
+// Checks that need the system to be running, like CPUID or the network
+// checks, are skipped. Every piece of evidence has its Root set to name.
+func ScanRoot(name string, fsys fs.FS) *Report {
+	r := &Report{}
+
+	check.OfflineRoot(r, name, fsys)
+	check.Consistency(r)
+
+	// Contradictions between signals in the root are about the root too.
+	for i := range r.Evidence {
+		r.Evidence[i].Root = name
+	}
+
+	return r
+}
+
+// ScanDir is ScanRoot for a directory, e.g. where a disk image is mounted.
+func ScanDir(dir string) *Report {
+	return ScanRoot(dir, os.DirFS(dir))
+}
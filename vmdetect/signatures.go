@@ -0,0 +1,42 @@
+/*
+ * Copyright 2024, Inspect Element Ltd <https://echo.ac>.
+ *
+ * See LICENCE <https://github.com/Inspect-Element-Ltd/vm/blob/master/LICENCE>
+ *
+ * signatures.go
+ * ---
+ * Last Modified: 20/10/2026 02:40PM (BST)
+ * Modified By: Gianluca Oliva <hello@gian.sh>
+ */
+
+package vmdetect
+
+import (
+	"github.com/Inspect-Element-Ltd/vm/internal/check"
+)
+
+// ErrNoReleaseKey is returned by UpdateSignatures when this build has no
+// release key pinned, so no bundle can be verified.
+var ErrNoReleaseKey = check.ErrNoReleaseKey
+
+// UpdateSignatures replaces the embedded signatures with a signed bundle.
+//
+// The bundle is only used if it's signed with the pinned release key and is
+// newer than the signatures embedded in this build, on any failure the
+// embedded signatures are used and the error is returned. Builds without a
+// pinned key return ErrNoReleaseKey for every bundle.
+//
+// Fetching the bundle is left to the caller, nothing here touches the network.
+func UpdateSignatures(bundle []byte) error {
+	return check.LoadSignatures(bundle)
+}
+
+// ResetSignatures goes back to the signatures embedded in this build.
+func ResetSignatures() {
+	check.ResetSignatures()
+}
+
+// SignatureVersion returns the version of the signatures currently in use.
+func SignatureVersion() int {
+	return check.SignatureVersion()
+}
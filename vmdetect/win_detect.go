@@ -5,7 +5,7 @@
  *
  * win_detect.go
  * ---
- * Last Modified: 11/05/2024 01:15AM (BST)
+ * Last Modified: 20/10/2026 02:40AM (BST)
  * Modified By: Gianluca Oliva <hello@gian.sh>
  */
 
@@ -17,14 +17,15 @@ import (
 	"github.com/Inspect-Element-Ltd/vm/internal/check"
 )
 
-func detectVM() (bool, string, string) {
-	if vm, vendor, why := check.Registry(); vm {
-		return vm, vendor, why
-	}
-
-	if vm, vendor, why := check.FileSystem(); vm {
-		return vm, vendor, why
-	}
-
-	return false, "", ""
+func detectVM(r *check.Report) {
+	check.Registry(r)
+	check.Firmware(r)
+	check.Services(r)
+	check.Devices(r)
+	check.WMI(r)
+	check.Sandbox(r)
+	check.Wine(r)
+	check.DeviceObjects(r)
+	check.FileSystem(r)
+	check.Passthrough(r)
 }